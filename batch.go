@@ -0,0 +1,193 @@
+package forest
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/honmaple/forest/binder"
+	"github.com/honmaple/forest/render"
+)
+
+type (
+	BatchConfig struct {
+		// MaxRequests limits the count of sub requests
+		MaxRequests int
+		// MaxConcurrency limits the count of sub requests executed at the same time
+		MaxConcurrency int
+		// InheritHeaders will be copied from the batch request to every sub request
+		InheritHeaders []string
+	}
+	BatchRequest struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers,omitempty"`
+		// Body is sent as JSON, or as the raw text if it's a JSON string,
+		// such as "a=1&b=2" with Content-Type application/x-www-form-urlencoded
+		Body json.RawMessage `json:"body,omitempty"`
+	}
+	BatchResponse struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    interface{}       `json:"body,omitempty"`
+	}
+	batchResponseWriter struct {
+		header http.Header
+		status int
+		body   bytes.Buffer
+	}
+	batchContextKey struct{}
+)
+
+var (
+	DefaultBatchConfig = BatchConfig{
+		MaxRequests:    20,
+		MaxConcurrency: 4,
+		InheritHeaders: []string{"Authorization", "Cookie"},
+	}
+	batchProxyHeaders = []string{headerForwarded, headerXForwardedFor, headerXForwardedHost, headerXForwardedProto, headerXRealIP}
+)
+
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *batchResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *batchResponseWriter) response() *BatchResponse {
+	resp := &BatchResponse{
+		Status:  w.status,
+		Headers: make(map[string]string, len(w.header)),
+	}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	for k := range w.header {
+		resp.Headers[k] = w.header.Get(k)
+	}
+	if w.body.Len() == 0 {
+		return resp
+	}
+	if strings.HasPrefix(w.header.Get(render.ContentType), render.ContentTypeJSON) && json.Valid(w.body.Bytes()) {
+		resp.Body = json.RawMessage(w.body.Bytes())
+	} else {
+		resp.Body = w.body.String()
+	}
+	return resp
+}
+
+func (e *Forest) serveBatch(parent *http.Request, config *BatchConfig, r *BatchRequest) *BatchResponse {
+	if r.Method == "" {
+		r.Method = http.MethodGet
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		return &BatchResponse{Status: http.StatusBadRequest, Body: "path must start with '/'"}
+	}
+	body, ctype := []byte(r.Body), render.ContentTypeJSON
+	if len(body) > 0 && body[0] == '"' {
+		var text string
+		if err := json.Unmarshal(body, &text); err != nil {
+			return &BatchResponse{Status: http.StatusBadRequest, Body: err.Error()}
+		}
+		body, ctype = []byte(text), render.ContentTypeTextCharsetUTF8
+	}
+	ctx := stdcontext.WithValue(parent.Context(), batchContextKey{}, true)
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(r.Method), r.Path, bytes.NewReader(body))
+	if err != nil {
+		return &BatchResponse{Status: http.StatusBadRequest, Body: err.Error()}
+	}
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	if len(body) > 0 && req.Header.Get(render.ContentType) == "" {
+		req.Header.Set(render.ContentType, ctype)
+	}
+	// sub request uses the same client as batch request, can't be spoofed by headers
+	for _, k := range batchProxyHeaders {
+		req.Header.Del(k)
+		if v := parent.Header.Values(k); len(v) > 0 {
+			req.Header[k] = v
+		}
+	}
+	for _, k := range config.InheritHeaders {
+		if v := parent.Header.Values(k); len(v) > 0 {
+			req.Header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	req.Host = parent.Host
+	req.RemoteAddr = parent.RemoteAddr
+	req.TLS = parent.TLS
+
+	w := &batchResponseWriter{header: make(http.Header)}
+	e.ServeHTTP(w, req)
+	return w.response()
+}
+
+// BatchHandler returns handler which executes a JSON array of sub requests
+// through the router and returns their responses
+func (e *Forest) BatchHandler(config BatchConfig) HandlerFunc {
+	if config.MaxRequests <= 0 {
+		config.MaxRequests = DefaultBatchConfig.MaxRequests
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = DefaultBatchConfig.MaxConcurrency
+	}
+	if config.InheritHeaders == nil {
+		config.InheritHeaders = DefaultBatchConfig.InheritHeaders
+	}
+	return func(c Context) error {
+		parent := c.Request()
+		if parent.Context().Value(batchContextKey{}) != nil {
+			return NewError(http.StatusBadRequest, "nested batch request is not allowed")
+		}
+		reqs := make([]*BatchRequest, 0)
+		if err := c.BindWith(&reqs, binder.JSON); err != nil {
+			return NewError(http.StatusBadRequest, err.Error())
+		}
+		if len(reqs) > config.MaxRequests {
+			return NewError(http.StatusRequestEntityTooLarge, "too many requests")
+		}
+
+		var (
+			wg    sync.WaitGroup
+			sem   = make(chan struct{}, config.MaxConcurrency)
+			resps = make([]*BatchResponse, len(reqs))
+		)
+		for i, req := range reqs {
+			if req == nil {
+				resps[i] = &BatchResponse{Status: http.StatusBadRequest}
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, req *BatchRequest) {
+				defer func() {
+					// a panic in sub request only fails its own response
+					if r := recover(); r != nil {
+						c.Logger().Errorf("forest: batch request %s %s panic: %v", req.Method, req.Path, r)
+						resps[i] = &BatchResponse{Status: http.StatusInternalServerError, Body: http.StatusText(http.StatusInternalServerError)}
+					}
+					<-sem
+					wg.Done()
+				}()
+				resps[i] = e.serveBatch(parent, &config, req)
+			}(i, req)
+		}
+		wg.Wait()
+		return c.JSON(http.StatusOK, resps)
+	}
+}
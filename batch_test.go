@@ -0,0 +1,100 @@
+package forest
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchHandler(t *testing.T) {
+	router := New(TrustedProxies("10.0.0.1"))
+	router.GET("/posts/{id}", func(c Context) error {
+		return c.JSON(http.StatusOK, H{"id": c.Param("id"), "ip": c.RealIP(), "auth": c.Request().Header.Get("Authorization")})
+	})
+	router.POST("/posts", func(c Context) error {
+		p := make(map[string]string)
+		if err := c.Bind(&p); err != nil {
+			return err
+		}
+		return c.String(http.StatusCreated, p["title"])
+	})
+	router.POST("/form", func(c Context) error {
+		return c.String(http.StatusOK, c.FormParam("title")+" "+c.Request().Header.Get("Content-Type"))
+	})
+	router.POST("/text", func(c Context) error {
+		b, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, string(b))
+	})
+	router.GET("/panic", func(c Context) error {
+		panic("panic")
+	})
+	router.POST("/batch", router.BatchHandler(BatchConfig{MaxRequests: 3}))
+
+	body := `[
+{"method": "GET", "path": "/posts/1", "headers": {"X-Forwarded-For": "2.2.2.2"}},
+{"method": "POST", "path": "/posts", "body": {"title": "forest"}},
+{"method": "POST", "path": "/batch", "body": []}
+]`
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	resps := make([]struct {
+		Status int             `json:"status"`
+		Body   json.RawMessage `json:"body"`
+	}, 0)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resps))
+	assert.Len(t, resps, 3)
+	assert.Equal(t, http.StatusOK, resps[0].Status)
+	assert.JSONEq(t, `{"id":"1","ip":"1.1.1.1","auth":"Bearer token"}`, string(resps[0].Body))
+	assert.Equal(t, http.StatusCreated, resps[1].Status)
+	assert.Equal(t, `"forest"`, string(resps[1].Body))
+	assert.Equal(t, http.StatusBadRequest, resps[2].Status)
+
+	router.Logger = &logger{log.New(io.Discard, "", 0)}
+	req = httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[{"method": "GET", "path": "/panic"},{"method": "GET", "path": "/posts/2"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	assert.NotPanics(t, func() {
+		router.ServeHTTP(rec, req)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resps))
+	assert.Len(t, resps, 2)
+	assert.Equal(t, http.StatusInternalServerError, resps[0].Status)
+	assert.Equal(t, http.StatusOK, resps[1].Status)
+
+	// string body is sent as raw text
+	req = httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[
+{"method": "POST", "path": "/form", "headers": {"Content-Type": "application/x-www-form-urlencoded"}, "body": "title=a+b"},
+{"method": "POST", "path": "/text", "body": "say \"hi\""}
+]`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resps))
+	assert.Len(t, resps, 2)
+	assert.Equal(t, `"a b application/x-www-form-urlencoded"`, string(resps[0].Body))
+	assert.Equal(t, `"say \"hi\""`, string(resps[1].Body))
+
+	req = httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[{},{},{},{}]`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
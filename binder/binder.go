@@ -1,11 +1,7 @@
 package binder
 
 import (
-	"encoding/json"
-	"encoding/xml"
-	"errors"
 	"net/http"
-	"strings"
 
 	"github.com/honmaple/forest/render"
 )
@@ -24,48 +20,52 @@ var (
 	Header        = HeaderBinder{"header"}
 	MultipartForm = MultipartFormBinder{"form"}
 	Params        = ParamsBinder{"param"}
+	Cookie        = CookieBinder{"cookie"}
 )
 
-type JSONBinder struct{}
-
-func (b JSONBinder) Bind(req *http.Request, dst interface{}) error {
-	return json.NewDecoder(req.Body).Decode(dst)
-}
-
-type XMLBinder struct{}
-
-func (b XMLBinder) Bind(req *http.Request, dst interface{}) error {
-	return xml.NewDecoder(req.Body).Decode(dst)
-}
-
 type QueryBinder struct {
 	TagName string
 }
 
 func (b QueryBinder) Bind(req *http.Request, dst interface{}) error {
-	return bindData(dst, req.URL.Query(), b.TagName)
+	return b.bind(req, dst, nil)
+}
+
+func (b QueryBinder) bind(req *http.Request, dst interface{}, nested *NestedConfig) error {
+	return sourceError(SourceQuery, bindNestedData(dst, req.URL.Query(), b.TagName, nested))
 }
 
 type FormBinder struct {
 	TagName string
 }
 
-func (b FormBinder) Bind(req *http.Request, dst interface{}) (err error) {
+func (b FormBinder) Bind(req *http.Request, dst interface{}) error {
+	return b.bind(req, dst, nil)
+}
+
+func (b FormBinder) bind(req *http.Request, dst interface{}, nested *NestedConfig) (err error) {
 	if err = req.ParseForm(); err != nil {
-		return err
+		return sourceError(SourceForm, err)
 	}
-	return bindData(dst, req.Form, b.TagName)
+	return sourceError(SourceForm, bindNestedData(dst, req.Form, b.TagName, nested))
 }
 
 type MultipartFormBinder struct {
 	TagName string
 }
 
-func (b MultipartFormBinder) Bind(req *http.Request, dst interface{}) (err error) {
+func (b MultipartFormBinder) Bind(req *http.Request, dst interface{}) error {
+	return b.bind(req, dst, nil)
+}
+
+func (b MultipartFormBinder) bind(req *http.Request, dst interface{}, nested *NestedConfig) (err error) {
 	if err = req.ParseMultipartForm(defaultMemory); err != nil {
-		return err
+		return sourceError(SourceForm, err)
 	}
-	return bindData(dst, req.PostForm, b.TagName)
+	if err = bindNestedData(dst, req.PostForm, b.TagName, nested); err != nil {
+		return sourceError(SourceForm, err)
+	}
+	return sourceError(SourceForm, bindFiles(dst, req.MultipartForm.File, b.TagName))
 }
 
 type HeaderBinder struct {
@@ -73,7 +73,19 @@ type HeaderBinder struct {
 }
 
 func (b HeaderBinder) Bind(req *http.Request, dst interface{}) error {
-	return bindData(dst, req.Header, b.TagName)
+	return sourceError(SourceHeader, bindData(dst, req.Header, b.TagName))
+}
+
+type CookieBinder struct {
+	TagName string
+}
+
+func (b CookieBinder) Bind(req *http.Request, dst interface{}) error {
+	m := make(map[string][]string)
+	for _, cookie := range req.Cookies() {
+		m[cookie.Name] = append(m[cookie.Name], cookie.Value)
+	}
+	return sourceError(SourceCookie, bindData(dst, m, b.TagName))
 }
 
 type ParamsBinder struct {
@@ -85,28 +97,50 @@ func (b ParamsBinder) Bind(params map[string]string, dst interface{}) error {
 	for k, v := range params {
 		m[k] = []string{v}
 	}
-	return bindData(dst, m, b.TagName)
+	return sourceError(SourceParam, bindData(dst, m, b.TagName))
 }
 
-func Bind(req *http.Request, dst interface{}) (err error) {
+func hasBody(req *http.Request) bool {
 	method := req.Method
-	if method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func Bind(req *http.Request, dst interface{}) (err error) {
+	if !hasBody(req) {
 		return Query.Bind(req, dst)
 	}
-	ctype := req.Header.Get(render.ContentType)
-	if strings.Contains(ctype, "/x-www-form-urlencoded") {
-		return Form.Bind(req, dst)
+	return BindBody(req, dst)
+}
+
+// BindAll binds header, cookie, query, body and params in order,
+// so that the latter will override the former if the field has multi tags.
+// Field without tag of source is bound by field name only if it has no tag of other sources
+func BindAll(req *http.Request, params map[string]string, dst interface{}) (err error) {
+	if err = Header.Bind(req, dst); err != nil {
+		return err
 	}
-	if strings.Contains(ctype, "/form-data") {
-		return MultipartForm.Bind(req, dst)
+	if err = Cookie.Bind(req, dst); err != nil {
+		return err
 	}
-	if strings.HasPrefix(ctype, render.ContentTypeJSON) {
-		return JSON.Bind(req, dst)
+	if err = Query.Bind(req, dst); err != nil {
+		return err
 	}
-	if strings.HasPrefix(ctype, render.ContentTypeXML) {
-		return XML.Bind(req, dst)
+	if hasBody(req) && req.ContentLength != 0 {
+		if err = BindBody(req, dst); err != nil {
+			return err
+		}
+	}
+	return Params.Bind(params, dst)
+}
+
+// BindBody binds request body by the registered binder of content type
+func BindBody(req *http.Request, dst interface{}) (err error) {
+	ctype := req.Header.Get(render.ContentType)
+	b, ok := Lookup(ctype)
+	if !ok {
+		return &Error{Value: ctype, Err: ErrUnsupportedMediaType}
 	}
-	return errors.New("unknown content type: " + ctype)
+	return b.Bind(req, dst)
 }
 
 func ParseForm(req *http.Request, maxmem int64) error {
@@ -0,0 +1,56 @@
+package binder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindAll(t *testing.T) {
+	type request struct {
+		UserID  string `header:"X-User-Id"`
+		Session string `cookie:"session"`
+		ID      int    `param:"id"`
+		Admin   bool   `json:"admin"`
+		Page    int    `query:"page"`
+		Name    string
+	}
+	req := httptest.NewRequest(http.MethodPost, "/?UserID=admin&Session=x&ID=2&Admin=true&page=3&Name=forest", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "user")
+	req.Header.Set("Page", "4")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})
+	req.AddCookie(&http.Cookie{Name: "UserID", Value: "admin"})
+
+	var dst request
+	assert.Nil(t, BindAll(req, map[string]string{"id": "1", "UserID": "admin"}, &dst))
+	assert.Equal(t, request{
+		UserID:  "user",
+		Session: "abc",
+		ID:      1,
+		Page:    3,
+		Name:    "forest",
+	}, dst)
+}
+
+func TestBindFieldName(t *testing.T) {
+	type request struct {
+		Title string `json:"title"`
+		Token string `header:"X-Token"`
+		Name  string
+	}
+	// single source binders skip fields tagged for other sources too
+	req := httptest.NewRequest(http.MethodGet, "/?Title=a&Token=b&Name=c", nil)
+	var dst request
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, request{Name: "c"}, dst)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("Title=a&Token=b&Name=c"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	dst = request{}
+	assert.Nil(t, Form.Bind(req, &dst))
+	assert.Equal(t, request{Name: "c"}, dst)
+}
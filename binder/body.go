@@ -0,0 +1,245 @@
+package binder
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxBodySize is the max size of json or xml body if MaxBodySize is 0
+var DefaultMaxBodySize int64 = 10 << 20
+
+var (
+	ErrBodyTooLarge = errors.New("request body too large")
+	ErrTrailingData = errors.New("unexpected data after top-level value")
+	ErrUnknownField = errors.New("unknown field")
+	// ErrUnsupportedMediaType is returned when no binder is registered for content type
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+type (
+	JSONBinder struct {
+		// MaxBodySize limits the size of body, 0 means DefaultMaxBodySize and -1 means no limit
+		MaxBodySize int64
+		// DisallowUnknownFields returns an error when the body has keys
+		// which do not match any non-ignored fields of the destination
+		DisallowUnknownFields bool
+		// UseNumber decodes number into interface{} as json.Number instead of float64
+		UseNumber bool
+		// AllowTrailingData allows multiple json values in body, only the first one will be bound
+		AllowTrailingData bool
+	}
+	XMLBinder struct {
+		// MaxBodySize limits the size of body, 0 means DefaultMaxBodySize and -1 means no limit
+		MaxBodySize int64
+	}
+	limitedReader struct {
+		r io.Reader
+		n int64
+	}
+)
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.n < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > r.n+1 {
+		p = p[:r.n+1]
+	}
+	n, err := r.r.Read(p)
+	if int64(n) > r.n {
+		n, r.n = int(r.n), -1
+		return n, ErrBodyTooLarge
+	}
+	r.n -= int64(n)
+	return n, err
+}
+
+func limitBody(req *http.Request, size int64) (io.Reader, error) {
+	if size == 0 {
+		size = DefaultMaxBodySize
+	}
+	if size < 0 {
+		return req.Body, nil
+	}
+	if req.ContentLength > size {
+		return nil, ErrBodyTooLarge
+	}
+	return &limitedReader{r: req.Body, n: size}, nil
+}
+
+func (b JSONBinder) Bind(req *http.Request, dst interface{}) error {
+	body, err := limitBody(req, b.MaxBodySize)
+	if err != nil {
+		return sourceError(SourceJSON, err)
+	}
+	// default values are set before decoding, the keys in body override them
+	if err := bindData(dst, nil, SourceJSON); err != nil {
+		return sourceError(SourceJSON, err)
+	}
+	dec := json.NewDecoder(body)
+	if b.UseNumber {
+		dec.UseNumber()
+	}
+	if b.DisallowUnknownFields {
+		if err := b.decodeStrict(dec, dst); err != nil {
+			return err
+		}
+	} else if err := dec.Decode(dst); err != nil {
+		return jsonError(err)
+	}
+	if b.AllowTrailingData {
+		return nil
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err != ErrBodyTooLarge {
+			err = ErrTrailingData
+		}
+		return &Error{Source: SourceJSON, Offset: dec.InputOffset(), Err: err}
+	}
+	return nil
+}
+
+func (b XMLBinder) Bind(req *http.Request, dst interface{}) error {
+	body, err := limitBody(req, b.MaxBodySize)
+	if err != nil {
+		return sourceError(SourceXML, err)
+	}
+	if err := bindData(dst, nil, SourceXML); err != nil {
+		return sourceError(SourceXML, err)
+	}
+	return sourceError(SourceXML, xml.NewDecoder(body).Decode(dst))
+}
+
+// decodeStrict decodes the first json value of dec into dst, and returns
+// the path of the first key which does not match any field of dst
+func (b JSONBinder) decodeStrict(dec *json.Decoder, dst interface{}) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return jsonError(err)
+	}
+	if name, ok := unknownField(raw, reflect.TypeOf(dst)); ok {
+		return &Error{Source: SourceJSON, Name: name, Err: ErrUnknownField}
+	}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.DisallowUnknownFields()
+	if b.UseNumber {
+		d.UseNumber()
+	}
+	err := d.Decode(dst)
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		// offset of raw is after the leading spaces of body
+		e.Offset += dec.InputOffset() - int64(len(raw))
+	}
+	return jsonError(err)
+}
+
+// unknownField walks json data with the type of destination like encoding/json,
+// and returns the path of the first key which does not match any field
+func unknownField(data []byte, typ reflect.Type) (string, bool) {
+	if typ == nil {
+		return "", false
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if ptr := reflect.PtrTo(typ); ptr.Implements(jsonUnmarshalerType) || ptr.Implements(textUnmarshalerType) {
+		return "", false
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		var m map[string]json.RawMessage
+		if json.Unmarshal(data, &m) != nil {
+			return "", false
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ftyp, ok := jsonFieldType(typ, key)
+			if !ok {
+				return key, true
+			}
+			if name, ok := unknownField(m[key], ftyp); ok {
+				return joinPath(key, name), true
+			}
+		}
+	case reflect.Map:
+		var m map[string]json.RawMessage
+		if json.Unmarshal(data, &m) != nil {
+			return "", false
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if name, ok := unknownField(m[key], typ.Elem()); ok {
+				return joinPath(indexPath(key), name), true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return "", false
+		}
+		for i, item := range items {
+			if name, ok := unknownField(item, typ.Elem()); ok {
+				return joinPath(indexPath(strconv.Itoa(i)), name), true
+			}
+		}
+	}
+	return "", false
+}
+
+// jsonFieldType returns the type of struct field matched by json key,
+// exact name is preferred over case-insensitive match
+func jsonFieldType(typ reflect.Type, key string) (reflect.Type, bool) {
+	var fold reflect.Type
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		if index := strings.IndexByte(tag, ','); index > -1 {
+			name = tag[:index]
+		}
+		if field.Anonymous && name == "" {
+			ftyp := field.Type
+			if ftyp.Kind() == reflect.Ptr {
+				ftyp = ftyp.Elem()
+			}
+			if ftyp.Kind() == reflect.Struct {
+				if t, ok := jsonFieldType(ftyp, key); ok {
+					return t, true
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if name == key {
+			return field.Type, true
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = field.Type
+		}
+	}
+	return fold, fold != nil
+}
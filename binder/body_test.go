@@ -0,0 +1,141 @@
+package binder
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONBinder(t *testing.T) {
+	type request struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	}
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest("POST", "/", strings.NewReader(body))
+	}
+
+	var dst request
+	assert.Nil(t, JSON.Bind(newRequest(`{"name": "forest", "value": 1, "unknown": 1}  `), &dst))
+	assert.Equal(t, "forest", dst.Name)
+	assert.Equal(t, float64(1), dst.Value)
+
+	err := JSON.Bind(newRequest(`{"name": "forest"} {"name": "forest"}`), &dst)
+	assert.True(t, errors.Is(err, ErrTrailingData))
+	err = JSON.Bind(newRequest(`{"name": "forest"}}`), &dst)
+	assert.True(t, errors.Is(err, ErrTrailingData))
+	assert.Nil(t, JSONBinder{AllowTrailingData: true}.Bind(newRequest(`{"name": "forest"} {}`), &dst))
+
+	err = JSONBinder{DisallowUnknownFields: true}.Bind(newRequest(`{"name": "forest", "unknown": 1}`), &dst)
+	e, ok := err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, "unknown", e.Name)
+	assert.True(t, errors.Is(err, ErrUnknownField))
+
+	type embed struct {
+		ID int `json:"id"`
+	}
+	type item struct {
+		SKU string `json:"sku"`
+	}
+	var strict struct {
+		embed
+		Name   string          `json:"name"`
+		Ignore string          `json:"-"`
+		Items  []item          `json:"items"`
+		Attrs  map[string]item `json:"attrs"`
+		Raw    json.RawMessage `json:"raw"`
+	}
+	strictBinder := JSONBinder{DisallowUnknownFields: true}
+	assert.Nil(t, strictBinder.Bind(newRequest(`{"id": 1, "NAME": "forest", "items": [{"sku": "a"}], "attrs": {"a": {"sku": "b"}}, "raw": {"any": 1}}`), &strict))
+	assert.Equal(t, 1, strict.ID)
+	assert.Equal(t, "forest", strict.Name)
+	for body, name := range map[string]string{
+		`{"Ignore": "x"}`:                     "Ignore",
+		`{"items": [{"sku": "a"}, {"x": 1}]}`: "items[1].x",
+		`{"attrs": {"a": {"x": 1}}}`:          "attrs[a].x",
+	} {
+		err = strictBinder.Bind(newRequest(body), &strict)
+		assert.True(t, errors.Is(err, ErrUnknownField), body)
+		e, ok = err.(*Error)
+		assert.True(t, ok, body)
+		assert.Equal(t, name, e.Name, body)
+	}
+	err = strictBinder.Bind(newRequest(`  {"name": 1}`), &strict)
+	e, ok = err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, "name", e.Name)
+	assert.Equal(t, int64(12), e.Offset)
+
+	assert.Nil(t, JSONBinder{UseNumber: true}.Bind(newRequest(`{"value": 12345678901234567890}`), &dst))
+	assert.Equal(t, json.Number("12345678901234567890"), dst.Value)
+
+	body := `{"name": "` + strings.Repeat("a", 100) + `"}`
+	// known content length
+	err = JSONBinder{MaxBodySize: 50}.Bind(newRequest(body), &dst)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+
+	// unknown content length
+	req := newRequest(body)
+	req.ContentLength = -1
+	req.Body = io.NopCloser(strings.NewReader(body))
+	err = JSONBinder{MaxBodySize: 50}.Bind(req, &dst)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+
+	req = newRequest(body + "   ")
+	req.ContentLength = -1
+	assert.Nil(t, JSONBinder{MaxBodySize: int64(len(body) + 3)}.Bind(req, &dst))
+	assert.Nil(t, JSONBinder{MaxBodySize: -1}.Bind(newRequest(body), &dst))
+
+	size := DefaultMaxBodySize
+	DefaultMaxBodySize = 50
+	defer func() { DefaultMaxBodySize = size }()
+	err = JSON.Bind(newRequest(body), &dst)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+}
+
+func TestXMLBinder(t *testing.T) {
+	type request struct {
+		Name string `xml:"name"`
+	}
+	var dst request
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`<request><name>forest</name></request>`))
+	assert.Nil(t, XML.Bind(req, &dst))
+	assert.Equal(t, "forest", dst.Name)
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`<request><name>`+strings.Repeat("a", 100)+`</name></request>`))
+	req.ContentLength = -1
+	err := XMLBinder{MaxBodySize: 50}.Bind(req, &dst)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+}
+
+func TestBodyBinderEmbedded(t *testing.T) {
+	type Meta struct {
+		ID int `json:"id" xml:"id"`
+	}
+	type request struct {
+		Meta `json:"meta" xml:"meta"`
+		Name string `json:"name" xml:"name" default:"forest"`
+	}
+
+	var dst request
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"meta": {"id": 1}}`))
+	assert.Nil(t, JSON.Bind(req, &dst))
+	assert.Equal(t, request{Meta: Meta{ID: 1}, Name: "forest"}, dst)
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"meta": {"id": 1}, "id": 2}`))
+	err := JSONBinder{DisallowUnknownFields: true}.Bind(req, &dst)
+	assert.True(t, errors.Is(err, ErrUnknownField))
+
+	dst = request{}
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`<request><id>2</id><name>maple</name></request>`))
+	assert.Nil(t, XML.Bind(req, &dst))
+	assert.Equal(t, request{Meta: Meta{ID: 2}, Name: "maple"}, dst)
+}
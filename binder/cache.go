@@ -0,0 +1,96 @@
+package binder
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type (
+	planKey struct {
+		typ     reflect.Type
+		tagName string
+	}
+	// fieldPlan is the parsed metadata of struct field for a tag name
+	fieldPlan struct {
+		index      int
+		name       string
+		inline     bool
+		file       bool
+		defaults   []string
+		timeFormat string
+		timeUTC    bool
+		field      reflect.StructField
+		// setter sets the field, or the element of slice field
+		setter fieldSetter
+	}
+	structPlan struct {
+		fields []*fieldPlan
+	}
+)
+
+var planCache sync.Map
+
+// cachedPlan returns the field plan of struct type, which is parsed only once for each tag name
+func cachedPlan(typ reflect.Type, tagName string) (*structPlan, error) {
+	key := planKey{typ, tagName}
+	if plan, ok := planCache.Load(key); ok {
+		return plan.(*structPlan), nil
+	}
+	plan, err := newStructPlan(typ, tagName)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := planCache.LoadOrStore(key, plan)
+	return actual.(*structPlan), nil
+}
+
+func newStructPlan(typ reflect.Type, tagName string) (*structPlan, error) {
+	plan := &structPlan{
+		fields: make([]*fieldPlan, 0, typ.NumField()),
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// unexported field can't be set
+		if field.PkgPath != "" {
+			continue
+		}
+		tag, inline, err := fieldTag(field, tagName)
+		if err != nil {
+			return nil, err
+		}
+		if tag == "-" {
+			continue
+		}
+		fp := &fieldPlan{
+			index:  i,
+			name:   tag,
+			inline: inline,
+			file:   field.Type == fileHeaderType || field.Type == fileHeaderSliceType,
+			field:  field,
+		}
+		if field.Type.Kind() == reflect.Slice {
+			fp.setter = newFieldSetter(field.Type.Elem())
+		} else {
+			fp.setter = newFieldSetter(field.Type)
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if field.Type.Kind() == reflect.Slice {
+				fp.defaults = strings.Split(def, ",")
+			} else {
+				fp.defaults = []string{def}
+			}
+		}
+		if format := field.Tag.Get("time_format"); format != "" {
+			// unix and unixnano are case insensitive, but layout is not
+			if lower := strings.ToLower(format); lower == "unix" || lower == "unixnano" {
+				format = lower
+			}
+			fp.timeFormat = format
+			fp.timeUTC, _ = strconv.ParseBool(field.Tag.Get("time_utc"))
+		}
+		plan.fields = append(plan.fields, fp)
+	}
+	return plan, nil
+}
@@ -0,0 +1,53 @@
+package binder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedPlan(t *testing.T) {
+	type embed struct {
+		Extra string `query:"extra"`
+	}
+	type testPlan struct {
+		embed
+		Name    string   `query:"name" default:"forest"`
+		Tags    []string `query:"tags" default:"a,b"`
+		Ignored string   `query:"-"`
+		private string
+		Inline  struct {
+			Code string `query:"code"`
+		} `query:",inline"`
+		Untagged string
+	}
+	typ := reflect.TypeOf(testPlan{})
+
+	plan, err := cachedPlan(typ, "query")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(plan.fields))
+	assert.Equal(t, "Name", plan.fields[0].field.Name)
+	assert.Equal(t, "name", plan.fields[0].name)
+	assert.Equal(t, []string{"forest"}, plan.fields[0].defaults)
+	assert.Equal(t, []string{"a", "b"}, plan.fields[1].defaults)
+	assert.True(t, plan.fields[2].inline)
+
+	plan1, err := cachedPlan(typ, "query")
+	assert.Nil(t, err)
+	assert.True(t, plan == plan1)
+
+	plan2, err := cachedPlan(typ, "form")
+	assert.Nil(t, err)
+	assert.False(t, plan == plan2)
+	// fields tagged for query only are not bound from form by field name
+	assert.Equal(t, 1, len(plan2.fields))
+	assert.Equal(t, "Untagged", plan2.fields[0].name)
+
+	type Embed struct{}
+	type badPlan struct {
+		Embed `query:"embed"`
+	}
+	_, err = cachedPlan(reflect.TypeOf(badPlan{}), "query")
+	assert.NotNil(t, err)
+}
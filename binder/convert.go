@@ -0,0 +1,162 @@
+package binder
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ConverterFunc converts string value to the registered type
+type ConverterFunc func(string) (interface{}, error)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	convertersMu sync.RWMutex
+	converters   = make(map[reflect.Type]ConverterFunc)
+)
+
+// RegisterConverter registers a converter for custom type such as decimal or uuid,
+// which has higher priority than TextUnmarshaler and json.Unmarshaler
+func RegisterConverter(typ reflect.Type, fn ConverterFunc) {
+	if typ == nil {
+		panic("binder: register converter with nil type")
+	}
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+
+	// field setters of cached plans may be resolved with the old converter
+	planCache.Range(func(key, _ interface{}) bool {
+		planCache.Delete(key)
+		return true
+	})
+	if fn == nil {
+		delete(converters, typ)
+		return
+	}
+	converters[typ] = fn
+}
+
+func lookupConverter(typ reflect.Type) (ConverterFunc, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	fn, ok := converters[typ]
+	return fn, ok
+}
+
+func convertField(fn ConverterFunc, value string, field reflect.Value) error {
+	v, err := fn(value)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(field.Type()) {
+		if !rv.Type().ConvertibleTo(field.Type()) {
+			return fmt.Errorf("converter returns %s, but field type is %s", rv.Type(), field.Type())
+		}
+		rv = rv.Convert(field.Type())
+	}
+	field.Set(rv)
+	return nil
+}
+
+func setDurationField(value string, field reflect.Value) error {
+	if value == "" {
+		value = "0"
+	}
+	d, err := time.ParseDuration(value)
+	if err == nil {
+		field.SetInt(int64(d))
+	}
+	return err
+}
+
+// setTimeField parses time with time_format tag, "unix" and "unixnano" are also allowed
+func setTimeField(value string, format string, utc bool, field reflect.Value) error {
+	switch field.Kind() {
+	case reflect.Ptr:
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		return setTimeField(value, format, utc, field.Elem())
+	case reflect.Struct:
+		if field.Type() != timeType {
+			return fmt.Errorf("time_format is not allowed for %s", field.Type())
+		}
+	default:
+		return fmt.Errorf("time_format is not allowed for %s", field.Type())
+	}
+	if value == "" {
+		field.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	switch format {
+	case "unix", "unixnano":
+		var n int64
+		n, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if format == "unix" {
+			t = time.Unix(n, 0)
+		} else {
+			t = time.Unix(0, n)
+		}
+	default:
+		loc := time.Local
+		if utc {
+			loc = time.UTC
+		}
+		t, err = time.ParseInLocation(format, value, loc)
+		if err != nil {
+			return err
+		}
+	}
+	if utc {
+		t = t.UTC()
+	}
+	field.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// setStructField sets field with values, which respects time_format, time_utc and default tags
+func setStructField(values []string, field *fieldPlan, vfield reflect.Value) error {
+	if len(values) == 0 {
+		if field.defaults == nil || !vfield.IsZero() {
+			return nil
+		}
+		values = field.defaults
+	}
+	if field.timeFormat != "" {
+		if vfield.Kind() != reflect.Slice {
+			return setTimeField(values[0], field.timeFormat, field.timeUTC, vfield)
+		}
+		slice := reflect.MakeSlice(vfield.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setTimeField(value, field.timeFormat, field.timeUTC, slice.Index(i)); err != nil {
+				return fieldError(indexPath(strconv.Itoa(i)), indexPath(strconv.Itoa(i)), values[i:], err)
+			}
+		}
+		vfield.Set(slice)
+		return nil
+	}
+	switch vfield.Kind() {
+	case reflect.Slice:
+		return setSliceField(values, vfield, field.setter)
+	case reflect.Map:
+		return fmt.Errorf("unsupported map field: %s", field.field.Name)
+	default:
+		return field.setter(values[0], vfield)
+	}
+}
@@ -0,0 +1,163 @@
+package binder
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUUID [2]string
+
+func TestBindDefault(t *testing.T) {
+	type testDefault struct {
+		Page    int      `query:"page" default:"1"`
+		Size    *int     `query:"size" default:"20"`
+		Sort    string   `query:"sort" default:"id"`
+		Tags    []string `query:"tags" default:"a,b"`
+		Keyword string   `query:"keyword"`
+	}
+	var dst testDefault
+	req := httptest.NewRequest("GET", "/?sort=name", nil)
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, 1, dst.Page)
+	assert.Equal(t, 20, *dst.Size)
+	assert.Equal(t, "name", dst.Sort)
+	assert.Equal(t, []string{"a", "b"}, dst.Tags)
+	assert.Equal(t, "", dst.Keyword)
+
+	// default value does not override value bound by other binder
+	dst = testDefault{Page: 3}
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, 3, dst.Page)
+	assert.Equal(t, "name", dst.Sort)
+
+	// fields tagged for query only are ignored by other binders, including defaults
+	dst = testDefault{}
+	assert.Nil(t, Header.Bind(req, &dst))
+	assert.Equal(t, testDefault{}, dst)
+
+	var bad struct {
+		Page int `query:"page" default:"x"`
+	}
+	assert.NotNil(t, Query.Bind(req, &bad))
+
+	// default values are also set for json and xml body if the key is missing
+	type testBodyDefault struct {
+		Page int    `json:"page" xml:"page" default:"1"`
+		Sort string `json:"sort" xml:"sort" default:"id"`
+	}
+	var body testBodyDefault
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"sort": "name"}`))
+	assert.Nil(t, JSON.Bind(req, &body))
+	assert.Equal(t, testBodyDefault{Page: 1, Sort: "name"}, body)
+
+	body = testBodyDefault{}
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`<testBodyDefault><page>2</page></testBodyDefault>`))
+	assert.Nil(t, XML.Bind(req, &body))
+	assert.Equal(t, testBodyDefault{Page: 2, Sort: "id"}, body)
+}
+
+func TestBindNonStruct(t *testing.T) {
+	// non struct destination is left untouched if there is nothing to bind
+	var (
+		s   string
+		ids []int
+	)
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.Nil(t, Query.Bind(req, &s))
+	assert.Nil(t, Query.Bind(req, &ids))
+	assert.Nil(t, Header.Bind(req, &ids))
+	assert.Equal(t, "", s)
+	assert.Nil(t, ids)
+
+	req = httptest.NewRequest("GET", "/?id=1", nil)
+	assert.NotNil(t, Query.Bind(req, &s))
+}
+
+func TestBindTime(t *testing.T) {
+	var dst struct {
+		Date    time.Time       `query:"date" time_format:"2006-01-02"`
+		UTCDate *time.Time      `query:"utc_date" time_format:"2006-01-02 15:04" time_utc:"true"`
+		Unix    time.Time       `query:"unix" time_format:"unix"`
+		Upper   time.Time       `query:"upper" time_format:"UNIX"`
+		Nano    time.Time       `query:"nano" time_format:"UnixNano"`
+		Dates   []time.Time     `query:"dates" time_format:"2006-01-02" time_utc:"1"`
+		Created time.Time       `query:"created"`
+		Timeout time.Duration   `query:"timeout"`
+		Delays  []time.Duration `query:"delays"`
+		Nested  struct {
+			Date time.Time `query:"date" time_format:"2006-01-02" time_utc:"1"`
+		} `query:"nested"`
+	}
+	req := httptest.NewRequest("GET", "/?date=2022-01-02&utc_date=2022-01-02+03:04&unix=1600000000&upper=1600000000&nano=1600000000000000001&dates=2022-01-01&dates=2022-01-02&created=2022-01-02T03:04:05Z&timeout=1m30s&delays=1s&delays=2ms&nested[date]=2022-03-04", nil)
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, time.Date(2022, 1, 2, 0, 0, 0, 0, time.Local), dst.Date)
+	assert.Equal(t, time.Date(2022, 1, 2, 3, 4, 0, 0, time.UTC), *dst.UTCDate)
+	assert.Equal(t, int64(1600000000), dst.Unix.Unix())
+	assert.Equal(t, int64(1600000000), dst.Upper.Unix())
+	assert.Equal(t, int64(1600000000000000001), dst.Nano.UnixNano())
+	assert.Equal(t, []time.Time{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)}, dst.Dates)
+	assert.Equal(t, time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC), dst.Created.UTC())
+	assert.Equal(t, 90*time.Second, dst.Timeout)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Millisecond}, dst.Delays)
+	assert.Equal(t, time.Date(2022, 3, 4, 0, 0, 0, 0, time.UTC), dst.Nested.Date)
+
+	req = httptest.NewRequest("GET", "/?date=2022/01/02", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+
+	req = httptest.NewRequest("GET", "/?timeout=10", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+
+	var bad struct {
+		Date string `query:"date" time_format:"2006-01-02"`
+	}
+	req = httptest.NewRequest("GET", "/?date=2022-01-02", nil)
+	assert.NotNil(t, Query.Bind(req, &bad))
+}
+
+func TestRegisterConverter(t *testing.T) {
+	// the cached plan is resolved again after registering converter
+	var before struct {
+		ID testUUID `query:"id"`
+	}
+	req := httptest.NewRequest("GET", "/?id=a-b", nil)
+	assert.NotNil(t, Query.Bind(req, &before))
+
+	typ := reflect.TypeOf(testUUID{})
+	RegisterConverter(typ, func(s string) (interface{}, error) {
+		parts := strings.SplitN(s, "-", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("invalid uuid")
+		}
+		return testUUID{parts[0], parts[1]}, nil
+	})
+	defer RegisterConverter(typ, nil)
+
+	var dst struct {
+		ID    testUUID   `query:"id"`
+		PID   *testUUID  `query:"pid"`
+		IDs   []testUUID `query:"ids"`
+		Items []struct {
+			ID testUUID `query:"id"`
+		} `query:"items"`
+	}
+	assert.Nil(t, Query.Bind(req, &before))
+	assert.Equal(t, testUUID{"a", "b"}, before.ID)
+
+	req = httptest.NewRequest("GET", "/?id=a-b&pid=c-d&ids=e-f&ids=g-h&items[0][id]=i-j", nil)
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, testUUID{"a", "b"}, dst.ID)
+	assert.Equal(t, testUUID{"c", "d"}, *dst.PID)
+	assert.Equal(t, []testUUID{{"e", "f"}, {"g", "h"}}, dst.IDs)
+	assert.Equal(t, testUUID{"i", "j"}, dst.Items[0].ID)
+
+	req = httptest.NewRequest("GET", "/?id=ab", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+
+	assert.Panics(t, func() { RegisterConverter(nil, nil) })
+}
@@ -0,0 +1,118 @@
+package binder
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const (
+	SourceQuery  = "query"
+	SourceForm   = "form"
+	SourceJSON   = "json"
+	SourceXML    = "xml"
+	SourceHeader = "header"
+	SourceCookie = "cookie"
+	SourceParam  = "param"
+	SourceCSV    = "csv"
+	SourceGob    = "gob"
+	SourceText   = "text"
+)
+
+// sourceTags are the tag names of sources which bind field by tag
+var sourceTags = []string{SourceQuery, SourceForm, SourceJSON, SourceXML, SourceHeader, SourceCookie, SourceParam}
+
+// Error is the error of binding, which records where the failure happened
+type Error struct {
+	// Source is where the value comes from, such as query, form, json, header or param
+	Source string `json:"source,omitempty"`
+	// Field is the path of struct field, such as Items[0].ID
+	Field string `json:"field,omitempty"`
+	// Name is the path of tag name, such as items[0].id
+	Name string `json:"name,omitempty"`
+	// Value is the raw value, or the json value type for json type error
+	Value string `json:"value,omitempty"`
+	// Offset is the byte offset of json syntax or type error
+	Offset int64 `json:"offset,omitempty"`
+	Err    error `json:"-"`
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("bind")
+	if e.Source != "" {
+		b.WriteString(" " + e.Source)
+	}
+	if e.Name != "" {
+		b.WriteString(" field " + strconv.Quote(e.Name))
+	} else if e.Field != "" {
+		b.WriteString(" field " + strconv.Quote(e.Field))
+	}
+	if e.Value != "" {
+		b.WriteString(" with value " + strconv.Quote(e.Value))
+	}
+	if e.Offset > 0 {
+		b.WriteString(" at offset " + strconv.FormatInt(e.Offset, 10))
+	}
+	if e.Err != nil {
+		b.WriteString(": " + e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func indexPath(key string) string {
+	return "[" + key + "]"
+}
+
+func joinPath(parent, child string) string {
+	if child == "" {
+		return parent
+	}
+	if parent == "" || child[0] == '[' {
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+// fieldError prefixes the field path if err is already an *Error
+func fieldError(field, name string, values []string, err error) error {
+	if e, ok := err.(*Error); ok {
+		e.Field = joinPath(field, e.Field)
+		e.Name = joinPath(name, e.Name)
+		return e
+	}
+	e := &Error{Field: field, Name: name, Err: err}
+	if len(values) > 0 {
+		e.Value = values[0]
+	}
+	return e
+}
+
+func sourceError(source string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*Error); ok {
+		if e.Source == "" {
+			e.Source = source
+		}
+		return e
+	}
+	return &Error{Source: source, Err: err}
+}
+
+func jsonError(err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *json.SyntaxError:
+		return &Error{Source: SourceJSON, Offset: e.Offset, Err: err}
+	case *json.UnmarshalTypeError:
+		return &Error{Source: SourceJSON, Field: e.Field, Name: e.Field, Value: e.Value, Offset: e.Offset, Err: err}
+	}
+	return sourceError(SourceJSON, err)
+}
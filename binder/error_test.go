@@ -0,0 +1,91 @@
+package binder
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindError(t *testing.T) {
+	var dst struct {
+		Page  int            `query:"page"`
+		Tags  []int          `query:"tags"`
+		Items []testItem     `query:"items"`
+		Meta  map[string]int `query:"meta"`
+		Token int            `header:"X-Token"`
+	}
+	tests := []struct {
+		url   string
+		field string
+		name  string
+		value string
+	}{
+		{"/?page=x", "Page", "page", "x"},
+		{"/?tags=1&tags=x", "Tags[1]", "tags[1]", "x"},
+		{"/?items[1][id]=x", "Items[1].ID", "items[1].id", "x"},
+		{"/?items[a][id]=1", "Items[a]", "items[a]", ""},
+		{"/?meta[a]=x", "Meta[a]", "meta[a]", "x"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.url, nil)
+		err := Query.Bind(req, &dst)
+
+		e, ok := err.(*Error)
+		assert.True(t, ok, test.url)
+		assert.Equal(t, SourceQuery, e.Source, test.url)
+		assert.Equal(t, test.field, e.Field, test.url)
+		assert.Equal(t, test.name, e.Name, test.url)
+		assert.Equal(t, test.value, e.Value, test.url)
+	}
+
+	req := httptest.NewRequest("GET", "/?page=x", nil)
+	err := Query.Bind(req, &dst)
+	assert.Equal(t, `bind query field "page" with value "x": strconv.ParseInt: parsing "x": invalid syntax`, err.Error())
+
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(err, &numErr))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Token", "x")
+	err = Header.Bind(req, &dst)
+	e, ok := err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, SourceHeader, e.Source)
+	assert.Equal(t, "X-Token", e.Name)
+}
+
+func TestBindJSONError(t *testing.T) {
+	var dst struct {
+		Name string `json:"name"`
+		User struct {
+			Age int `json:"age"`
+		} `json:"user"`
+	}
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "a",}`))
+	err := JSON.Bind(req, &dst)
+	e, ok := err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, SourceJSON, e.Source)
+	assert.Equal(t, int64(14), e.Offset)
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"user": {"age": "1"}}`))
+	err = JSON.Bind(req, &dst)
+	e, ok = err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, SourceJSON, e.Source)
+	assert.Equal(t, "user.age", e.Field)
+	assert.Equal(t, "string", e.Value)
+	assert.True(t, e.Offset > 0)
+	assert.Contains(t, e.Error(), `bind json field "user.age"`)
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`<a>`))
+	err = XML.Bind(req, &dst)
+	e, ok = err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, SourceXML, e.Source)
+}
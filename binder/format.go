@@ -0,0 +1,158 @@
+package binder
+
+import (
+	"encoding/csv"
+	"encoding/gob"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+var (
+	CSV  = CSVBinder{TagName: "csv"}
+	Gob  = GobBinder{}
+	Text = TextBinder{TagName: "text"}
+
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// CSVBinder binds csv body into *[][]string, or slice of struct with the first record as header
+type CSVBinder struct {
+	TagName string
+	// MaxBodySize limits the size of body, 0 means DefaultMaxBodySize and -1 means no limit
+	MaxBodySize int64
+}
+
+func (b CSVBinder) Bind(req *http.Request, dst interface{}) error {
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Slice {
+		return sourceError(SourceCSV, errors.New("csv must be bound to a pointer of slice"))
+	}
+	val = val.Elem()
+
+	body, err := limitBody(req, b.MaxBodySize)
+	if err != nil {
+		return sourceError(SourceCSV, err)
+	}
+	records, err := csv.NewReader(body).ReadAll()
+	if err != nil {
+		return sourceError(SourceCSV, err)
+	}
+	if val.Type() == reflect.TypeOf(records) {
+		val.Set(reflect.ValueOf(records))
+		return nil
+	}
+
+	elemType := val.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return sourceError(SourceCSV, errors.New("csv must be bound to [][]string or slice of struct"))
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	header := records[0]
+	slice := reflect.MakeSlice(val.Type(), 0, len(records)-1)
+	for i, record := range records[1:] {
+		values := make(map[string][]string, len(header))
+		for j, name := range header {
+			if j < len(record) {
+				values[name] = append(values[name], record[j])
+			}
+		}
+		elem := reflect.New(structType)
+		if err := bindData(elem.Interface(), values, b.TagName); err != nil {
+			return sourceError(SourceCSV, fieldError(indexPath(strconv.Itoa(i)), indexPath(strconv.Itoa(i)), nil, err))
+		}
+		if elemType.Kind() == reflect.Ptr {
+			slice = reflect.Append(slice, elem)
+		} else {
+			slice = reflect.Append(slice, elem.Elem())
+		}
+	}
+	val.Set(slice)
+	return nil
+}
+
+// GobBinder binds encoding/gob body, which is not registered by default because
+// gob is not meant for untrusted input, use Register(render.ContentTypeGob, Gob) to enable it
+type GobBinder struct {
+	// MaxBodySize limits the size of body, 0 means DefaultMaxBodySize and -1 means no limit
+	MaxBodySize int64
+}
+
+func (b GobBinder) Bind(req *http.Request, dst interface{}) error {
+	body, err := limitBody(req, b.MaxBodySize)
+	if err != nil {
+		return sourceError(SourceGob, err)
+	}
+	return sourceError(SourceGob, gob.NewDecoder(body).Decode(dst))
+}
+
+// TextBinder binds text body into *string, *[]byte or the string and []byte fields with tag
+type TextBinder struct {
+	TagName string
+	// MaxBodySize limits the size of body, 0 means DefaultMaxBodySize and -1 means no limit
+	MaxBodySize int64
+}
+
+func (b TextBinder) Bind(req *http.Request, dst interface{}) error {
+	body, err := limitBody(req, b.MaxBodySize)
+	if err != nil {
+		return sourceError(SourceText, err)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return sourceError(SourceText, err)
+	}
+
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return sourceError(SourceText, errors.New("text must be bound to a pointer"))
+	}
+	val = val.Elem()
+	if setText(data, val) {
+		return nil
+	}
+	if val.Kind() == reflect.Struct {
+		bound := false
+		for i := 0; i < val.NumField(); i++ {
+			field := val.Type().Field(i)
+			if _, ok := field.Tag.Lookup(b.TagName); !ok || field.PkgPath != "" {
+				continue
+			}
+			if !setText(data, val.Field(i)) {
+				return sourceError(SourceText, fieldError(field.Name, field.Name, nil, errors.New("text must be bound to string or []byte")))
+			}
+			bound = true
+		}
+		if bound {
+			return nil
+		}
+	}
+	return sourceError(SourceText, errors.New("text must be bound to string, []byte or the field with "+b.TagName+" tag"))
+}
+
+func setText(data []byte, field reflect.Value) bool {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(string(data))
+	case field.Type() == bytesType:
+		field.SetBytes(data)
+	default:
+		return false
+	}
+	return true
+}
@@ -5,13 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf(([]*multipart.FileHeader)(nil))
+)
+
 func bindData(value interface{}, dst map[string][]string, tagName string) error {
-	if value == nil || len(dst) == 0 {
+	if value == nil {
 		return nil
 	}
 	val := reflect.ValueOf(value)
@@ -20,144 +26,222 @@ func bindData(value interface{}, dst map[string][]string, tagName string) error
 		val = val.Elem()
 	}
 
-	if val.Kind() == reflect.Map {
-		for k, v := range dst {
-			val.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v[0]))
-		}
+	// nothing to bind, only struct has default values
+	if len(dst) == 0 && val.Kind() != reflect.Struct {
 		return nil
 	}
+	if val.Kind() == reflect.Map {
+		return setMapField(dst, val)
+	}
 
 	if val.Kind() != reflect.Struct {
 		return errors.New("bind must be a struct")
 	}
+	if !val.CanAddr() {
+		return errors.New("bind must be a pointer")
+	}
 
-	t := val.Type()
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		tag := field.Tag.Get(tagName)
-		if tag == "-" {
+	plan, err := cachedPlan(val.Type(), tagName)
+	if err != nil {
+		return err
+	}
+	for _, field := range plan.fields {
+		vfield := val.Field(field.index)
+		if field.inline {
+			if vfield.Kind() == reflect.Ptr {
+				if vfield.IsNil() {
+					continue
+				}
+				vfield = vfield.Elem()
+			}
+			if err := bindData(vfield.Addr().Interface(), dst, tagName); err != nil {
+				return err
+			}
 			continue
 		}
-
-		vfield := val.Field(i)
-		if !vfield.CanSet() {
+		if field.file {
 			continue
 		}
-
-		inline := false
-		if field.Anonymous {
-			if tag != "" {
-				return fmt.Errorf("anonymous struct field: %s  are not allowed set tag", field.Name)
-			}
-			inline = true
-		} else {
-			opts := strings.Split(tag, ",")
-			if len(opts) > 1 {
-				for _, flag := range opts[1:] {
-					switch flag {
-					case "inline":
-						inline = true
-					}
-				}
-				tag = opts[0]
-			}
+		if err := setStructField(dst[field.name], field, vfield); err != nil {
+			return fieldError(field.field.Name, field.name, dst[field.name], err)
 		}
-		kind := field.Type.Kind()
-		if inline {
-			if kind == reflect.Ptr {
+	}
+	return nil
+}
+
+func bindFiles(value interface{}, files map[string][]*multipart.FileHeader, tagName string) error {
+	if value == nil || len(files) == 0 {
+		return nil
+	}
+	val := reflect.ValueOf(value)
+
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	if !val.CanAddr() {
+		return errors.New("bind must be a pointer")
+	}
+
+	plan, err := cachedPlan(val.Type(), tagName)
+	if err != nil {
+		return err
+	}
+	for _, field := range plan.fields {
+		vfield := val.Field(field.index)
+		if field.inline {
+			if vfield.Kind() == reflect.Ptr {
 				if vfield.IsNil() {
 					continue
 				}
 				vfield = vfield.Elem()
 			}
-			if err := bindData(vfield.Addr().Interface(), dst, tagName); err != nil {
+			if err := bindFiles(vfield.Addr().Interface(), files, tagName); err != nil {
 				return err
 			}
 			continue
 		}
-		if tag == "" {
-			tag = field.Name
-		}
-		values, ok := dst[tag]
-		if !ok {
+		if !field.file {
 			continue
 		}
-		if kind == reflect.Slice {
-			if err := setSliceField(values, vfield); err != nil {
-				return err
-			}
+		headers, ok := files[field.name]
+		if !ok || len(headers) == 0 {
 			continue
 		}
-		if err := setField(kind, values[0], vfield); err != nil {
-			return err
+		if field.field.Type == fileHeaderType {
+			vfield.Set(reflect.ValueOf(headers[0]))
+		} else {
+			vfield.Set(reflect.ValueOf(headers))
 		}
 	}
 	return nil
 }
 
-// This function is stolen from echo
-func unmarshalField(kind reflect.Kind, value string, field reflect.Value) (bool, error) {
-	switch kind {
-	case reflect.Ptr:
-		if field.IsNil() {
-			// Initialize the pointer to a nil value
-			field.Set(reflect.New(field.Type().Elem()))
+// fieldTag returns the tag name of struct field, "-" means the field should be skipped
+func fieldTag(field reflect.StructField, tagName string) (string, bool, error) {
+	tag := field.Tag.Get(tagName)
+	if tag == "-" {
+		return tag, false, nil
+	}
+	inline := false
+	if field.Anonymous {
+		if tag != "" && tagName != SourceJSON && tagName != SourceXML {
+			return "", false, fmt.Errorf("anonymous struct field: %s  are not allowed set tag", field.Name)
 		}
-		return unmarshalField(reflect.Struct, value, field.Elem())
-	default:
-		fieldValue := field.Addr().Interface()
-		if unmarshaler, ok := fieldValue.(encoding.TextUnmarshaler); ok {
-			return true, unmarshaler.UnmarshalText([]byte(value))
+		// like encoding/json, tagged anonymous field is a named field,
+		// but encoding/xml always inlines anonymous field
+		tag = strings.Split(tag, ",")[0]
+		if tagName == SourceXML {
+			tag = ""
+		}
+		inline = tag == ""
+	} else {
+		opts := strings.Split(tag, ",")
+		if len(opts) > 1 {
+			for _, flag := range opts[1:] {
+				switch flag {
+				case "inline":
+					inline = true
+				}
+			}
+			tag = opts[0]
 		}
-		if unmarshaler, ok := fieldValue.(json.Unmarshaler); ok {
-			return true, unmarshaler.UnmarshalJSON([]byte(value))
+	}
+	if tag == "" {
+		// field tagged for another source only, such as `header:"X-User-Id"`,
+		// must not be bound by field name from this source
+		for _, name := range sourceTags {
+			if name != tagName && field.Tag.Get(name) != "" {
+				return "-", false, nil
+			}
 		}
-		return false, nil
+		tag = field.Name
 	}
+	return tag, inline, nil
 }
 
-func setField(kind reflect.Kind, value string, field reflect.Value) error {
-	if ok, err := unmarshalField(kind, value, field); ok {
-		return err
+// fieldSetter sets the field with string value, which is resolved once for each type
+type fieldSetter func(string, reflect.Value) error
+
+// newFieldSetter resolves how to set the field of typ, the priority is
+// converter, time.Duration, TextUnmarshaler, json.Unmarshaler and then kind
+func newFieldSetter(typ reflect.Type) fieldSetter {
+	if fn, ok := lookupConverter(typ); ok {
+		return func(value string, field reflect.Value) error {
+			return convertField(fn, value, field)
+		}
+	}
+	if typ == durationType {
+		return setDurationField
+	}
+	if typ.Kind() != reflect.Ptr {
+		ptr := reflect.PtrTo(typ)
+		if ptr.Implements(textUnmarshalerType) {
+			return func(value string, field reflect.Value) error {
+				return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+			}
+		}
+		if ptr.Implements(jsonUnmarshalerType) {
+			return func(value string, field reflect.Value) error {
+				return field.Addr().Interface().(json.Unmarshaler).UnmarshalJSON([]byte(value))
+			}
+		}
 	}
 
-	switch kind {
+	switch typ.Kind() {
 	case reflect.Ptr:
-		return setField(field.Elem().Kind(), value, field.Elem())
+		elem := newFieldSetter(typ.Elem())
+		return func(value string, field reflect.Value) error {
+			if field.IsNil() {
+				field.Set(reflect.New(typ.Elem()))
+			}
+			return elem(value, field.Elem())
+		}
 	case reflect.Bool:
-		return setBoolField(value, field)
-	case reflect.Int:
-		return setIntField(value, 0, field)
-	case reflect.Int8:
-		return setIntField(value, 8, field)
-	case reflect.Int16:
-		return setIntField(value, 16, field)
-	case reflect.Int32:
-		return setIntField(value, 32, field)
-	case reflect.Int64:
-		return setIntField(value, 64, field)
-	case reflect.Uint:
-		return setUintField(value, 0, field)
-	case reflect.Uint8:
-		return setUintField(value, 8, field)
-	case reflect.Uint16:
-		return setUintField(value, 16, field)
-	case reflect.Uint32:
-		return setUintField(value, 32, field)
-	case reflect.Uint64:
-		return setUintField(value, 64, field)
-	case reflect.Float32:
-		return setFloatField(value, 32, field)
-	case reflect.Float64:
-		return setFloatField(value, 64, field)
+		return setBoolField
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bitSize := typ.Bits()
+		return func(value string, field reflect.Value) error {
+			return setIntField(value, bitSize, field)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bitSize := typ.Bits()
+		return func(value string, field reflect.Value) error {
+			return setUintField(value, bitSize, field)
+		}
+	case reflect.Float32, reflect.Float64:
+		bitSize := typ.Bits()
+		return func(value string, field reflect.Value) error {
+			return setFloatField(value, bitSize, field)
+		}
 	case reflect.String:
-		field.SetString(value)
-		return nil
+		return func(value string, field reflect.Value) error {
+			field.SetString(value)
+			return nil
+		}
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			return func(string, reflect.Value) error {
+				return fmt.Errorf("unsupported interface type: %s", typ)
+			}
+		}
+		return func(value string, field reflect.Value) error {
+			field.Set(reflect.ValueOf(value))
+			return nil
+		}
 	default:
-		return errors.New("unknown field type")
+		return func(string, reflect.Value) error {
+			return errors.New("unknown field type")
+		}
 	}
 }
 
+func setField(value string, field reflect.Value) error {
+	return newFieldSetter(field.Type())(value, field)
+}
+
 func setIntField(value string, bitSize int, field reflect.Value) error {
 	if value == "" {
 		value = "0"
@@ -202,18 +286,59 @@ func setFloatField(value string, bitSize int, field reflect.Value) error {
 	return err
 }
 
-func setSliceField(values []string, field reflect.Value) error {
+// setSliceField sets slice with values, nil setter means resolving the setter of slice element
+func setSliceField(values []string, field reflect.Value, setter fieldSetter) error {
 	vlen := len(values)
 	if vlen == 0 {
 		return nil
 	}
-	sliceOf := field.Type().Elem().Kind()
+	if setter == nil {
+		setter = newFieldSetter(field.Type().Elem())
+	}
 	slice := reflect.MakeSlice(field.Type(), vlen, vlen)
 	for j := 0; j < vlen; j++ {
-		if err := setField(sliceOf, values[j], slice.Index(j)); err != nil {
-			return err
+		if err := setter(values[j], slice.Index(j)); err != nil {
+			return fieldError(indexPath(strconv.Itoa(j)), indexPath(strconv.Itoa(j)), values[j:], err)
 		}
 	}
 	field.Set(slice)
 	return nil
 }
+
+func setMapField(values map[string][]string, field reflect.Value) error {
+	if field.IsNil() {
+		if !field.CanSet() {
+			return errors.New("bind must be a non-nil map")
+		}
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+	keyType := field.Type().Key()
+	for k, v := range values {
+		key := reflect.New(keyType).Elem()
+		if err := setField(k, key); err != nil {
+			return fieldError(indexPath(k), k, []string{k}, err)
+		}
+		elem := reflect.New(field.Type().Elem()).Elem()
+		if err := setMapValue(v, elem); err != nil {
+			return fieldError(indexPath(k), k, v, err)
+		}
+		field.SetMapIndex(key, elem)
+	}
+	return nil
+}
+
+// setMapValue sets all values for slice, and the first value for others,
+// interface{} will be string or []string with multiple values
+func setMapValue(values []string, field reflect.Value) error {
+	switch kind := field.Kind(); {
+	case kind == reflect.Slice:
+		return setSliceField(values, field, nil)
+	case len(values) == 0:
+		return nil
+	case kind == reflect.Interface && field.NumMethod() == 0 && len(values) > 1:
+		field.Set(reflect.ValueOf(values))
+		return nil
+	default:
+		return setField(values[0], field)
+	}
+}
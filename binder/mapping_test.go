@@ -0,0 +1,134 @@
+package binder
+
+import (
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindMap(t *testing.T) {
+	values := map[string][]string{
+		"a": {"1", "2"},
+		"b": {"3"},
+	}
+
+	m1 := make(map[string]string)
+	assert.Nil(t, bindData(m1, values, "query"))
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, m1)
+
+	var m2 map[string]int
+	assert.Nil(t, bindData(&m2, values, "query"))
+	assert.Equal(t, map[string]int{"a": 1, "b": 3}, m2)
+
+	var m3 map[string][]int
+	assert.Nil(t, bindData(&m3, values, "query"))
+	assert.Equal(t, map[string][]int{"a": {1, 2}, "b": {3}}, m3)
+
+	var m4 map[string]interface{}
+	assert.Nil(t, bindData(&m4, values, "query"))
+	assert.Equal(t, map[string]interface{}{"a": []string{"1", "2"}, "b": "3"}, m4)
+
+	var m5 map[int]string
+	assert.NotNil(t, bindData(&m5, values, "query"))
+
+	var m6 map[string]bool
+	assert.NotNil(t, bindData(&m6, values, "query"))
+
+	var m7 map[string]struct{}
+	assert.NotNil(t, bindData(&m7, values, "query"))
+
+	var m8 map[string]string
+	assert.NotNil(t, bindData(m8, values, "query"))
+
+	m9 := make(map[int]*int)
+	assert.Nil(t, bindData(m9, map[string][]string{"1": {"2"}}, "query"))
+	assert.Equal(t, 2, *m9[1])
+}
+
+func TestBindNestedMap(t *testing.T) {
+	var dst struct {
+		Counts map[string]int            `query:"counts"`
+		Tags   map[string][]string       `query:"tags"`
+		Extra  map[string]interface{}    `query:"extra"`
+		IDs    map[int]string            `query:"ids"`
+		Items  map[string]map[string]int `query:"items"`
+	}
+	req := httptest.NewRequest("GET", "/?counts[a]=1&counts.b=2&tags[x]=1&tags[x]=2&extra[k]=v&ids[1]=one&items[a][b]=1", nil)
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, dst.Counts)
+	assert.Equal(t, map[string][]string{"x": {"1", "2"}}, dst.Tags)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, dst.Extra)
+	assert.Equal(t, map[int]string{1: "one"}, dst.IDs)
+	assert.Equal(t, map[string]map[string]int{"a": {"b": 1}}, dst.Items)
+
+	req = httptest.NewRequest("GET", "/?counts[a]=x", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+
+	req = httptest.NewRequest("GET", "/?ids[x]=one", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+}
+
+type benchForm struct {
+	ID      int       `query:"id"`
+	Name    string    `query:"name"`
+	Email   string    `query:"email"`
+	Age     int       `query:"age"`
+	Score   float64   `query:"score"`
+	Active  bool      `query:"active"`
+	Tags    []string  `query:"tags"`
+	Page    int       `query:"page" default:"1"`
+	Size    int       `query:"size" default:"20"`
+	Created time.Time `query:"created" time_format:"2006-01-02"`
+	Ignored string    `query:"-"`
+}
+
+func BenchmarkBindData(b *testing.B) {
+	values := map[string][]string{
+		"id":      {"1"},
+		"name":    {"forest"},
+		"email":   {"forest@example.com"},
+		"age":     {"18"},
+		"score":   {"99.5"},
+		"active":  {"true"},
+		"tags":    {"a", "b"},
+		"created": {"2022-01-02"},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var dst benchForm
+		if err := bindData(&dst, values, "query"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBindQuery(b *testing.B) {
+	req := httptest.NewRequest("GET", "/?id=1&name=forest&age=18&tags=a&tags=b&created=2022-01-02", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var dst benchForm
+		if err := Query.Bind(req, &dst); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBindNonPointer(t *testing.T) {
+	type request struct {
+		Name string `query:"name" form:"name"`
+	}
+	req := httptest.NewRequest("GET", "/?name=forest", nil)
+	assert.NotPanics(t, func() {
+		err := Query.Bind(req, request{})
+		assert.EqualError(t, err, "bind query: bind must be a pointer")
+	})
+	assert.NotPanics(t, func() {
+		err := bindFiles(request{}, map[string][]*multipart.FileHeader{"name": {{}}}, "form")
+		assert.EqualError(t, err, "bind must be a pointer")
+	})
+}
@@ -0,0 +1,325 @@
+package binder
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// NestedConfig configures the syntax of nested form and query keys:
+	//
+	//	user[name]=a, items[0][id]=1, items[][sku]=a, tags[]=a  with Bracket
+	//	user.name=a,  items.0.id=1,   items[].sku=a             with Dot
+	NestedConfig struct {
+		Bracket bool
+		Dot     bool
+		// MaxIndex limits the max index of slice to prevent memory abuse, 0 means DefaultNestedConfig.MaxIndex
+		MaxIndex int
+	}
+	formNode struct {
+		values   []string
+		children map[string]*formNode
+	}
+	nestedBinder struct {
+		binder interface {
+			bind(*http.Request, interface{}, *NestedConfig) error
+		}
+		config NestedConfig
+	}
+)
+
+var (
+	DefaultNestedConfig = NestedConfig{
+		Bracket:  true,
+		Dot:      true,
+		MaxIndex: 1000,
+	}
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// NewQueryBinder returns query binder with custom nested config instead of DefaultNestedConfig
+func NewQueryBinder(tagName string, config NestedConfig) Binder {
+	return nestedBinder{binder: QueryBinder{TagName: tagName}, config: config}
+}
+
+// NewFormBinder returns form binder with custom nested config instead of DefaultNestedConfig
+func NewFormBinder(tagName string, config NestedConfig) Binder {
+	return nestedBinder{binder: FormBinder{TagName: tagName}, config: config}
+}
+
+// NewMultipartFormBinder returns multipart form binder with custom nested config instead of DefaultNestedConfig
+func NewMultipartFormBinder(tagName string, config NestedConfig) Binder {
+	return nestedBinder{binder: MultipartFormBinder{TagName: tagName}, config: config}
+}
+
+func (b nestedBinder) Bind(req *http.Request, dst interface{}) error {
+	return b.binder.bind(req, dst, &b.config)
+}
+
+// parseNestedKey splits items[0][id] or items.0.id into [items 0 id]
+func parseNestedKey(key string, config *NestedConfig) ([]string, bool) {
+	var (
+		segs  = make([]string, 0, 4)
+		start = 0
+	)
+	for i := 0; i < len(key); {
+		switch {
+		case config.Bracket && key[i] == '[':
+			if i == 0 {
+				return nil, false
+			}
+			if i > start {
+				segs = append(segs, key[start:i])
+			}
+			end := strings.IndexByte(key[i:], ']')
+			if end < 0 {
+				return nil, false
+			}
+			segs = append(segs, key[i+1:i+end])
+			i, start = i+end+1, i+end+1
+			if config.Dot && i < len(key) && key[i] == '.' {
+				i, start = i+1, i+1
+			}
+		case config.Dot && key[i] == '.':
+			if i == start {
+				return nil, false
+			}
+			segs = append(segs, key[start:i])
+			i, start = i+1, i+1
+		default:
+			i++
+		}
+	}
+	if start < len(key) {
+		segs = append(segs, key[start:])
+	}
+	return segs, len(segs) > 1
+}
+
+func newFormNode(dst map[string][]string, config *NestedConfig) *formNode {
+	var root *formNode
+	for key, values := range dst {
+		if !strings.ContainsAny(key, "[.") {
+			continue
+		}
+		segs, ok := parseNestedKey(key, config)
+		if !ok {
+			continue
+		}
+		if root == nil {
+			root = &formNode{}
+		}
+		node := root
+		for _, seg := range segs {
+			node = node.child(seg)
+		}
+		node.values = append(node.values, values...)
+	}
+	return root
+}
+
+func (n *formNode) child(key string) *formNode {
+	if n.children == nil {
+		n.children = make(map[string]*formNode)
+	}
+	child, ok := n.children[key]
+	if !ok {
+		child = &formNode{}
+		n.children[key] = child
+	}
+	return child
+}
+
+// size returns the max count of values in node and its children
+func (n *formNode) size() int {
+	size := len(n.values)
+	for _, child := range n.children {
+		if s := child.size(); s > size {
+			size = s
+		}
+	}
+	return size
+}
+
+// nth returns node that only has the nth value of node and its children,
+// which is used to bind items[][id]=1&items[][id]=2
+func (n *formNode) nth(i int) *formNode {
+	node := &formNode{}
+	if i < len(n.values) {
+		node.values = n.values[i : i+1]
+	}
+	for key, child := range n.children {
+		if c := child.nth(i); len(c.values) > 0 || len(c.children) > 0 {
+			if node.children == nil {
+				node.children = make(map[string]*formNode)
+			}
+			node.children[key] = c
+		}
+	}
+	return node
+}
+
+func isScalarType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	if _, ok := lookupConverter(t); ok {
+		return true
+	}
+	return reflect.PtrTo(t).Implements(textUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType)
+}
+
+func bindNode(val reflect.Value, node *formNode, tagName string, config *NestedConfig) error {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		if isScalarType(val.Type()) {
+			break
+		}
+		plan, err := cachedPlan(val.Type(), tagName)
+		if err != nil {
+			return err
+		}
+		for _, field := range plan.fields {
+			vfield := val.Field(field.index)
+			if field.inline {
+				if err := bindNode(vfield, node, tagName, config); err != nil {
+					return err
+				}
+				continue
+			}
+			// file is only bound from multipart files
+			if field.file {
+				continue
+			}
+			child, ok := node.children[field.name]
+			if !ok {
+				continue
+			}
+			if field.timeFormat != "" && len(child.children) == 0 {
+				if err := setStructField(child.values, field, vfield); err != nil {
+					return fieldError(field.field.Name, field.name, child.values, err)
+				}
+				continue
+			}
+			if err := bindNode(vfield, child, tagName, config); err != nil {
+				return fieldError(field.field.Name, field.name, child.values, err)
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if len(node.children) == 0 {
+			return setSliceField(node.values, val, nil)
+		}
+		// indexed children are bound first, then items[] are appended after them,
+		// so that the result doesn't depend on the order of map
+		nodes := make([]*formNode, 0)
+		for key, child := range node.children {
+			if key == "" {
+				continue
+			}
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 {
+				return fieldError(indexPath(key), indexPath(key), nil, errors.New("invalid slice index"))
+			}
+			if index > config.MaxIndex {
+				return fieldError(indexPath(key), indexPath(key), nil, fmt.Errorf("slice index is out of range %d", config.MaxIndex))
+			}
+			if index >= len(nodes) {
+				nodes = append(nodes, make([]*formNode, index-len(nodes)+1)...)
+			}
+			nodes[index] = child
+		}
+		if child, ok := node.children[""]; ok {
+			for i, size := 0, child.size(); i < size; i++ {
+				nodes = append(nodes, child.nth(i))
+			}
+		}
+		if len(nodes) > config.MaxIndex+1 {
+			return fmt.Errorf("slice length %d is out of range %d", len(nodes), config.MaxIndex+1)
+		}
+		slice := reflect.MakeSlice(val.Type(), len(nodes), len(nodes))
+		reflect.Copy(slice, val)
+		for i, n := range nodes {
+			if n == nil {
+				continue
+			}
+			if err := bindNode(slice.Index(i), n, tagName, config); err != nil {
+				return fieldError(indexPath(strconv.Itoa(i)), indexPath(strconv.Itoa(i)), n.values, err)
+			}
+		}
+		val.Set(slice)
+		return nil
+	case reflect.Map:
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+		var (
+			err     error
+			keyType = val.Type().Key()
+		)
+		for k, child := range node.children {
+			key := reflect.New(keyType).Elem()
+			if err := setField(k, key); err != nil {
+				return fieldError(indexPath(k), indexPath(k), []string{k}, err)
+			}
+			elem := reflect.New(val.Type().Elem()).Elem()
+			if len(child.children) == 0 {
+				err = setMapValue(child.values, elem)
+			} else {
+				err = bindNode(elem, child, tagName, config)
+			}
+			if err != nil {
+				return fieldError(indexPath(k), indexPath(k), child.values, err)
+			}
+			val.SetMapIndex(key, elem)
+		}
+		return nil
+	}
+	if len(node.values) == 0 {
+		return nil
+	}
+	return setField(node.values[0], val)
+}
+
+// bindNestedData binds flat keys first, then nested keys such as user[name] or user.name,
+// nil config means DefaultNestedConfig
+func bindNestedData(value interface{}, dst map[string][]string, tagName string, nested *NestedConfig) error {
+	if err := bindData(value, dst, tagName); err != nil {
+		return err
+	}
+	config := DefaultNestedConfig
+	if nested != nil {
+		config = *nested
+	}
+	if config.MaxIndex <= 0 {
+		config.MaxIndex = DefaultNestedConfig.MaxIndex
+	}
+	if !config.Bracket && !config.Dot {
+		return nil
+	}
+	node := newFormNode(dst, &config)
+	if node == nil {
+		return nil
+	}
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	return bindNode(val, node, tagName, &config)
+}
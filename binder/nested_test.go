@@ -0,0 +1,153 @@
+package binder
+
+import (
+	"mime/multipart"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testItem struct {
+		ID  int    `form:"id" query:"id"`
+		SKU string `form:"sku" query:"sku"`
+	}
+	testNestedForm struct {
+		Name    string   `form:"name"`
+		User    testItem `form:"user"`
+		Address *struct {
+			City string `form:"city"`
+		} `form:"address"`
+		Items []testItem          `form:"items"`
+		Tags  []string            `form:"tags"`
+		Meta  map[string]string   `form:"meta"`
+		Attrs map[string]testItem `form:"attrs"`
+	}
+)
+
+func TestParseNestedKey(t *testing.T) {
+	config := &NestedConfig{Bracket: true, Dot: true}
+	tests := map[string][]string{
+		"user[name]":       {"user", "name"},
+		"items[0][id]":     {"items", "0", "id"},
+		"items[].sku":      {"items", "", "sku"},
+		"address.city":     {"address", "city"},
+		"items.0.id":       {"items", "0", "id"},
+		"a[b].c[d]":        {"a", "b", "c", "d"},
+		"items[0][id]here": {"items", "0", "id", "here"},
+	}
+	for key, segs := range tests {
+		s, ok := parseNestedKey(key, config)
+		assert.True(t, ok, key)
+		assert.Equal(t, segs, s, key)
+	}
+
+	for _, key := range []string{"name", "[name]", "user[name", ".name"} {
+		_, ok := parseNestedKey(key, config)
+		assert.False(t, ok, key)
+	}
+
+	_, ok := parseNestedKey("address.city", &NestedConfig{Bracket: true})
+	assert.False(t, ok)
+	_, ok = parseNestedKey("user[name]", &NestedConfig{Dot: true})
+	assert.False(t, ok)
+}
+
+func TestNestedFormBind(t *testing.T) {
+	form := url.Values{}
+	form.Set("name", "forest")
+	form.Set("user[id]", "1")
+	form.Set("user[sku]", "u1")
+	form.Set("address.city", "beijing")
+	form.Set("items[1][id]", "2")
+	form.Set("items[0][id]", "1")
+	form.Set("items[0].sku", "a")
+	form.Add("tags[]", "t1")
+	form.Add("tags[]", "t2")
+	form.Set("meta[color]", "red")
+	form.Set("attrs[x][id]", "9")
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var dst testNestedForm
+	assert.Nil(t, Form.Bind(req, &dst))
+	assert.Equal(t, "forest", dst.Name)
+	assert.Equal(t, testItem{ID: 1, SKU: "u1"}, dst.User)
+	assert.Equal(t, "beijing", dst.Address.City)
+	assert.Equal(t, []testItem{{ID: 1, SKU: "a"}, {ID: 2}}, dst.Items)
+	assert.Equal(t, []string{"t1", "t2"}, dst.Tags)
+	assert.Equal(t, map[string]string{"color": "red"}, dst.Meta)
+	assert.Equal(t, map[string]testItem{"x": {ID: 9}}, dst.Attrs)
+}
+
+func TestNestedFormFile(t *testing.T) {
+	var dst struct {
+		Name   string                  `form:"name"`
+		Avatar *multipart.FileHeader   `form:"avatar"`
+		Photos []*multipart.FileHeader `form:"photos"`
+	}
+	// form fields can't fake uploaded files
+	for _, body := range []string{
+		"name=a&avatar.Filename=x&avatar.Size=1&photos[0].Filename=y",
+		"name=a&avatar[Filename]=x&avatar[Size]=1&photos[][Filename]=y",
+	} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		assert.Nil(t, Form.Bind(req, &dst))
+		assert.Equal(t, "a", dst.Name)
+		assert.Nil(t, dst.Avatar)
+		assert.Nil(t, dst.Photos)
+	}
+}
+
+func TestNestedMixedIndex(t *testing.T) {
+	var dst struct {
+		Items []testItem `query:"items"`
+	}
+	// indexed items are bound first, then items[] are appended
+	for i := 0; i < 20; i++ {
+		dst.Items = nil
+		req := httptest.NewRequest("GET", "/?items[][id]=3&items[1][id]=2&items[0][id]=1&items[][id]=4&items[0][sku]=a", nil)
+		assert.Nil(t, Query.Bind(req, &dst))
+		assert.Equal(t, []testItem{{ID: 1, SKU: "a"}, {ID: 2}, {ID: 3}, {ID: 4}}, dst.Items)
+	}
+}
+
+func TestNestedQueryBind(t *testing.T) {
+	var dst struct {
+		Items []testItem `query:"items"`
+	}
+	req := httptest.NewRequest("GET", "/?items[].id=1&items[].sku=a&items[].id=2&items[].sku=b", nil)
+	assert.Nil(t, Query.Bind(req, &dst))
+	assert.Equal(t, []testItem{{ID: 1, SKU: "a"}, {ID: 2, SKU: "b"}}, dst.Items)
+
+	dst.Items = nil
+	req = httptest.NewRequest("GET", "/?items[100000][id]=1", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+	assert.Nil(t, dst.Items)
+
+	req = httptest.NewRequest("GET", "/?items[x][id]=1", nil)
+	assert.NotNil(t, Query.Bind(req, &dst))
+
+	config := NestedConfig{Bracket: true}
+	query := NewQueryBinder("query", config)
+	req = httptest.NewRequest("GET", "/?items.0.id=1", nil)
+	assert.Nil(t, query.Bind(req, &dst))
+	assert.Nil(t, dst.Items)
+
+	// MaxIndex 0 means the default limit
+	req = httptest.NewRequest("GET", "/?items[1][id]=1", nil)
+	assert.Nil(t, query.Bind(req, &dst))
+	assert.Equal(t, []testItem{{}, {ID: 1}}, dst.Items)
+
+	dst.Items = nil
+	config.MaxIndex = 1
+	query = NewQueryBinder("query", config)
+	req = httptest.NewRequest("GET", "/?items[2][id]=1", nil)
+	assert.NotNil(t, query.Bind(req, &dst))
+	assert.Equal(t, 1000, DefaultNestedConfig.MaxIndex)
+}
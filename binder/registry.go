@@ -0,0 +1,77 @@
+package binder
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/honmaple/forest/render"
+)
+
+// BinderFunc is an adapter to allow the use of function as Binder
+type BinderFunc func(*http.Request, interface{}) error
+
+func (fn BinderFunc) Bind(req *http.Request, dst interface{}) error {
+	return fn(req, dst)
+}
+
+var (
+	bindersMu sync.RWMutex
+	// default binders use the package variables, so that the replaced binder also works
+	binders = map[string]Binder{
+		"application/x-www-form-urlencoded": BinderFunc(func(req *http.Request, dst interface{}) error {
+			return Form.Bind(req, dst)
+		}),
+		render.ContentTypeMultipartForm: BinderFunc(func(req *http.Request, dst interface{}) error {
+			return MultipartForm.Bind(req, dst)
+		}),
+		render.ContentTypeJSON: BinderFunc(func(req *http.Request, dst interface{}) error {
+			return JSON.Bind(req, dst)
+		}),
+		render.ContentTypeXML: BinderFunc(func(req *http.Request, dst interface{}) error {
+			return XML.Bind(req, dst)
+		}),
+		"text/xml": BinderFunc(func(req *http.Request, dst interface{}) error {
+			return XML.Bind(req, dst)
+		}),
+		render.ContentTypeCSV: BinderFunc(func(req *http.Request, dst interface{}) error {
+			return CSV.Bind(req, dst)
+		}),
+		render.ContentTypeText: BinderFunc(func(req *http.Request, dst interface{}) error {
+			return Text.Bind(req, dst)
+		}),
+	}
+)
+
+// Register registers binder with media type, nil binder means unregister
+func Register(mediaType string, b Binder) {
+	mediaType = render.MediaType(mediaType)
+	if mediaType == "" {
+		panic("binder: register with empty media type")
+	}
+	bindersMu.Lock()
+	defer bindersMu.Unlock()
+
+	if b == nil {
+		delete(binders, mediaType)
+		return
+	}
+	binders[mediaType] = b
+}
+
+// Lookup returns the binder of content type, the structured syntax suffix
+// such as +json or +xml is used if the media type is not registered
+func Lookup(contentType string) (Binder, bool) {
+	mediaType := render.MediaType(contentType)
+
+	bindersMu.RLock()
+	defer bindersMu.RUnlock()
+
+	if b, ok := binders[mediaType]; ok {
+		return b, true
+	}
+	if suffix, ok := render.MediaTypeSuffix(mediaType); ok {
+		b, ok := binders[suffix]
+		return b, ok
+	}
+	return nil, false
+}
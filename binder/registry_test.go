@@ -0,0 +1,118 @@
+package binder
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/forest/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	for _, ctype := range []string{
+		"application/json",
+		"Application/JSON; charset=UTF-8",
+		"application/problem+json",
+		"application/vnd.api+json; charset=utf-8",
+	} {
+		b, ok := Lookup(ctype)
+		assert.True(t, ok, ctype)
+		assert.NotNil(t, b, ctype)
+	}
+	for _, ctype := range []string{"", "application/unknown", "application/vnd+unknown", "application/json+"} {
+		_, ok := Lookup(ctype)
+		assert.False(t, ok, ctype)
+	}
+
+	Register("Application/X-Custom", BinderFunc(func(req *http.Request, dst interface{}) error {
+		*dst.(*string) = "custom"
+		return nil
+	}))
+	defer Register("application/x-custom", nil)
+
+	var s string
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Content-Type", "application/x-custom; v=1")
+	assert.Nil(t, BindBody(req, &s))
+	assert.Equal(t, "custom", s)
+
+	assert.Panics(t, func() { Register("", nil) })
+}
+
+func TestBindBodyFormats(t *testing.T) {
+	type record struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}
+	newRequest := func(ctype string, body string) *http.Request {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", ctype)
+		return req
+	}
+
+	var records []record
+	assert.Nil(t, BindBody(newRequest("text/csv; header=present", "id,name\n1,a\n2,b\n"), &records))
+	assert.Equal(t, []record{{1, "a"}, {2, "b"}}, records)
+
+	var precords []*record
+	assert.Nil(t, BindBody(newRequest("text/csv", "name,id\na,1\n"), &precords))
+	assert.Equal(t, []*record{{1, "a"}}, precords)
+
+	var raw [][]string
+	assert.Nil(t, BindBody(newRequest("text/csv", "id,name\n1,a\n"), &raw))
+	assert.Equal(t, [][]string{{"id", "name"}, {"1", "a"}}, raw)
+
+	err := BindBody(newRequest("text/csv", "id,name\n1,a\nx,b\n"), &records)
+	e, ok := err.(*Error)
+	assert.True(t, ok)
+	assert.Equal(t, SourceCSV, e.Source)
+	assert.Equal(t, "[1].ID", e.Field)
+	assert.Equal(t, "x", e.Value)
+	assert.NotNil(t, BindBody(newRequest("text/csv", "id\n1\n"), &record{}))
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, gob.NewEncoder(buf).Encode(record{ID: 1, Name: "gob"}))
+	var r record
+	// gob is opt-in
+	err = BindBody(newRequest("application/x-gob", buf.String()), &r)
+	assert.True(t, errors.Is(err, ErrUnsupportedMediaType))
+	Register(render.ContentTypeGob, Gob)
+	defer Register(render.ContentTypeGob, nil)
+	assert.Nil(t, BindBody(newRequest("application/x-gob", buf.String()), &r))
+	assert.Equal(t, record{ID: 1, Name: "gob"}, r)
+
+	var (
+		text string
+		data []byte
+		body struct {
+			Body  string `text:""`
+			Raw   []byte `text:""`
+			Other string
+		}
+	)
+	assert.Nil(t, BindBody(newRequest("text/plain; charset=utf-8", "hello"), &text))
+	assert.Equal(t, "hello", text)
+	assert.Nil(t, BindBody(newRequest("text/plain", "hello"), &data))
+	assert.Equal(t, []byte("hello"), data)
+	assert.Nil(t, BindBody(newRequest("text/plain", "hello"), &body))
+	assert.Equal(t, "hello", body.Body)
+	assert.Equal(t, []byte("hello"), body.Raw)
+	assert.Equal(t, "", body.Other)
+	assert.NotNil(t, BindBody(newRequest("text/plain", "hello"), &r))
+
+	err = BindBody(newRequest("text/plain", strings.Repeat("a", 100)), &text)
+	assert.Nil(t, err)
+	err = TextBinder{MaxBodySize: 10}.Bind(newRequest("text/plain", strings.Repeat("a", 100)), &text)
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+
+	assert.Nil(t, BindBody(newRequest("application/vnd.api+json", `{"ID": 2}`), &r))
+	assert.Equal(t, 2, r.ID)
+	err = BindBody(newRequest("application/unknown", `{"ID": 2}`), &r)
+	assert.True(t, errors.Is(err, ErrUnsupportedMediaType))
+	assert.Equal(t, `bind with value "application/unknown": unsupported media type`, err.Error())
+}
@@ -0,0 +1,451 @@
+package binder
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	StructValidator interface {
+		Validate(interface{}) error
+	}
+	// Validator validates struct fields with rules in tag, such as
+	//
+	//	Name     string `json:"name" validate:"required,min=3,max=20"`
+	//	Role     string `json:"role" validate:"oneof=admin user"`
+	//	Email    string `json:"email" validate:"omitempty,email"`
+	//	Password string `json:"password" validate:"required,regexp=^\\w+$"`
+	//	Confirm  string `json:"confirm" validate:"eqfield=Password"`
+	//
+	// regexp must be the last rule because it may contain comma
+	Validator struct {
+		TagName  string
+		NameTags []string
+	}
+	FieldError struct {
+		// Field is the path of struct field, such as Items[0].Name
+		Field string `json:"field"`
+		// Name is the path of name from NameTags, such as items[0].name
+		Name  string `json:"name"`
+		Rule  string `json:"rule"`
+		Param string `json:"param,omitempty"`
+	}
+	ValidationErrors []*FieldError
+	validateRule     struct {
+		name   string
+		param  string
+		number float64
+		re     *regexp.Regexp
+	}
+	validateKey struct {
+		typ      reflect.Type
+		tagName  string
+		nameTags string
+	}
+	// validateField is the parsed rules of struct field, which is checked only once for each type
+	validateField struct {
+		index     int
+		field     string
+		name      string
+		anonymous bool
+		rules     []validateRule
+	}
+	validatePlan struct {
+		fields []*validateField
+	}
+	// validatePath is the path of value being validated, which is
+	// only formatted when a FieldError is recorded
+	validatePath struct {
+		parent *validatePath
+		field  *validateField
+		index  int
+	}
+)
+
+var (
+	DefaultValidator = &Validator{
+		TagName:  "validate",
+		NameTags: []string{"json", "form", "query", "param", "header", "xml"},
+	}
+	timeType      = reflect.TypeOf(time.Time{})
+	validateCache sync.Map
+)
+
+func (e *FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("%s: failed on the '%s' rule", e.Name, e.Rule)
+	}
+	return fmt.Sprintf("%s: failed on the '%s=%s' rule", e.Name, e.Rule, e.Param)
+}
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func parseRules(typ reflect.Type, tag string) ([]validateRule, error) {
+	rules := make([]validateRule, 0)
+	for tag != "" {
+		var s string
+		if strings.HasPrefix(tag, "regexp=") {
+			s, tag = tag, ""
+		} else if index := strings.IndexByte(tag, ','); index > -1 {
+			s, tag = tag[:index], tag[index+1:]
+		} else {
+			s, tag = tag, ""
+		}
+		if s == "" {
+			continue
+		}
+		rule := validateRule{name: s}
+		if index := strings.IndexByte(s, '='); index > -1 {
+			rule.name, rule.param = s[:index], s[index+1:]
+		}
+		switch rule.name {
+		case "required", "omitempty", "email", "url", "oneof":
+		case "min", "max", "len":
+			number, err := strconv.ParseFloat(rule.param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid param of '%s' rule: %q", rule.name, rule.param)
+			}
+			rule.number = number
+		case "regexp":
+			re, err := regexp.Compile(rule.param)
+			if err != nil {
+				return nil, fmt.Errorf("invalid param of '%s' rule: %s", rule.name, err)
+			}
+			rule.re = re
+		case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+			if _, ok := typ.FieldByName(rule.param); !ok {
+				return nil, fmt.Errorf("unknown field of '%s' rule: %q", rule.name, rule.param)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rule '%s'", rule.name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// plan returns the validate rules of struct type, invalid rules are reported
+// as configuration error instead of panic at request time
+func (v *Validator) plan(typ reflect.Type) (*validatePlan, error) {
+	key := validateKey{typ, v.TagName, strings.Join(v.NameTags, ",")}
+	if plan, ok := validateCache.Load(key); ok {
+		return plan.(*validatePlan), nil
+	}
+	plan := &validatePlan{
+		fields: make([]*validateField, 0, typ.NumField()),
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		vf := &validateField{
+			index:     i,
+			field:     field.Name,
+			name:      v.fieldName(field),
+			anonymous: field.Anonymous,
+		}
+		if tag := field.Tag.Get(v.TagName); !field.Anonymous && tag != "" && tag != "-" {
+			rules, err := parseRules(typ, tag)
+			if err != nil {
+				return nil, fmt.Errorf("binder: %s.%s: %s", typ, field.Name, err)
+			}
+			vf.rules = rules
+		}
+		plan.fields = append(plan.fields, vf)
+	}
+	actual, _ := validateCache.LoadOrStore(key, plan)
+	return actual.(*validatePlan), nil
+}
+
+func (v *Validator) fieldName(field reflect.StructField) string {
+	for _, tagName := range v.NameTags {
+		tag := field.Tag.Get(tagName)
+		if index := strings.IndexByte(tag, ','); index > -1 {
+			tag = tag[:index]
+		}
+		if tag != "" && tag != "-" {
+			return tag
+		}
+	}
+	return field.Name
+}
+
+func (v *Validator) Validate(value interface{}) error {
+	val := reflect.ValueOf(value)
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	errs := make(ValidationErrors, 0)
+	if err := v.validate(val, nil, &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *Validator) validate(val reflect.Value, path *validatePath, errs *ValidationErrors) error {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		if val.Type() == timeType {
+			return nil
+		}
+	case reflect.Slice, reflect.Array:
+		// []byte or []int can't have rules, skip them to avoid walking large arrays
+		if !hasRules(val.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < val.Len(); i++ {
+			if err := v.validate(val.Index(i), &validatePath{parent: path, index: i}, errs); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	plan, err := v.plan(val.Type())
+	if err != nil {
+		return err
+	}
+	for _, field := range plan.fields {
+		vfield := val.Field(field.index)
+		if field.anonymous {
+			if err := v.validate(vfield, path, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		if !vfield.CanInterface() {
+			continue
+		}
+
+		fpath := &validatePath{parent: path, field: field}
+		for _, rule := range field.rules {
+			if rule.name == "omitempty" {
+				if isEmpty(vfield) {
+					break
+				}
+				continue
+			}
+			if !v.check(rule, vfield, val) {
+				fieldPath, namePath := fpath.format()
+				*errs = append(*errs, &FieldError{Field: fieldPath, Name: namePath, Rule: rule.name, Param: rule.param})
+				break
+			}
+		}
+		if err := v.validate(vfield, fpath, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format returns the path of struct field and the path of name, such as Items[0].Name and items[0].name
+func (p *validatePath) format() (string, string) {
+	if p == nil {
+		return "", ""
+	}
+	field, name := p.parent.format()
+	if p.field == nil {
+		index := "[" + strconv.Itoa(p.index) + "]"
+		return field + index, name + index
+	}
+	if field != "" {
+		field, name = field+".", name+"."
+	}
+	return field + p.field.field, name + p.field.name
+}
+
+// hasRules reports whether value of typ may have fields with rules
+func hasRules(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Struct:
+		return typ != timeType
+	case reflect.Slice, reflect.Array:
+		return hasRules(typ.Elem())
+	default:
+		return false
+	}
+}
+
+func isEmpty(val reflect.Value) bool {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return val.IsNil()
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return val.Len() == 0
+	}
+	return val.IsZero()
+}
+
+func (v *Validator) check(rule validateRule, val reflect.Value, parent reflect.Value) bool {
+	if rule.name == "required" {
+		return !isEmpty(val)
+	}
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return true
+		}
+		val = val.Elem()
+	}
+	switch rule.name {
+	case "min":
+		return compareParam(val, rule.number) >= 0
+	case "max":
+		return compareParam(val, rule.number) <= 0
+	case "len":
+		return compareParam(val, rule.number) == 0
+	case "oneof":
+		s := fmt.Sprint(val.Interface())
+		for _, p := range strings.Fields(rule.param) {
+			if s == p {
+				return true
+			}
+		}
+		return false
+	case "regexp":
+		return val.Kind() == reflect.String && rule.re.MatchString(val.String())
+	case "email":
+		if val.Kind() != reflect.String {
+			return false
+		}
+		addr, err := mail.ParseAddress(val.String())
+		return err == nil && addr.Address == val.String()
+	case "url":
+		if val.Kind() != reflect.String {
+			return false
+		}
+		u, err := url.ParseRequestURI(val.String())
+		return err == nil && u.Scheme != "" && u.Host != ""
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		other := parent.FieldByName(rule.param)
+		if !other.IsValid() {
+			return false
+		}
+		for other.Kind() == reflect.Ptr {
+			if other.IsNil() {
+				return false
+			}
+			other = other.Elem()
+		}
+		c, ok := compareValue(val, other)
+		if !ok {
+			return false
+		}
+		switch rule.name {
+		case "eqfield":
+			return c == 0
+		case "nefield":
+			return c != 0
+		case "gtfield":
+			return c > 0
+		case "gtefield":
+			return c >= 0
+		case "ltfield":
+			return c < 0
+		default:
+			return c <= 0
+		}
+	}
+	// rules are checked when parsing plan
+	return true
+}
+
+func compareFloat(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareParam compares number with param, or length of string, slice and map with param
+func compareParam(val reflect.Value, p float64) int {
+	switch val.Kind() {
+	case reflect.String:
+		return compareFloat(float64(utf8.RuneCountInString(val.String())), p)
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return compareFloat(float64(val.Len()), p)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareFloat(float64(val.Int()), p)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareFloat(float64(val.Uint()), p)
+	case reflect.Float32, reflect.Float64:
+		return compareFloat(val.Float(), p)
+	}
+	return 0
+}
+
+func compareValue(a, b reflect.Value) (int, bool) {
+	if a.Type() == timeType && b.Type() == timeType {
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		if ta.Before(tb) {
+			return -1, true
+		}
+		if ta.After(tb) {
+			return 1, true
+		}
+		return 0, true
+	}
+	switch a.Kind() {
+	case reflect.String:
+		if b.Kind() != reflect.String {
+			return 0, false
+		}
+		return strings.Compare(a.String(), b.String()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch b.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return compareFloat(float64(a.Int()), float64(b.Int())), true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch b.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return compareFloat(float64(a.Uint()), float64(b.Uint())), true
+		}
+	case reflect.Float32, reflect.Float64:
+		switch b.Kind() {
+		case reflect.Float32, reflect.Float64:
+			return compareFloat(a.Float(), b.Float()), true
+		}
+	}
+	return 0, false
+}
+
+func Validate(value interface{}) error {
+	return DefaultValidator.Validate(value)
+}
@@ -0,0 +1,137 @@
+package binder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testAddress struct {
+		City string `json:"city" validate:"required"`
+	}
+	testUser struct {
+		Name      string        `json:"name" validate:"required,min=3,max=5"`
+		Age       int           `json:"age" validate:"min=18"`
+		Role      string        `json:"role" validate:"oneof=admin user"`
+		Code      string        `form:"code" validate:"len=4,regexp=^[0-9,]+$"`
+		Email     string        `json:"email" validate:"omitempty,email"`
+		Website   string        `json:"website" validate:"omitempty,url"`
+		Password  string        `json:"password"`
+		Confirm   string        `json:"confirm" validate:"eqfield=Password"`
+		Start     time.Time     `json:"start"`
+		End       time.Time     `json:"end" validate:"gtfield=Start"`
+		Tags      []string      `json:"tags" validate:"max=2"`
+		Address   *testAddress  `json:"address"`
+		Addresses []testAddress `json:"addresses"`
+	}
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Now()
+	u := &testUser{
+		Name:      "forest",
+		Age:       20,
+		Role:      "admin",
+		Code:      "1,23",
+		Email:     "a@example.com",
+		Website:   "https://example.com",
+		Password:  "123",
+		Confirm:   "123",
+		Start:     now,
+		End:       now.Add(time.Hour),
+		Tags:      []string{"a", "b"},
+		Address:   &testAddress{City: "a"},
+		Addresses: []testAddress{{City: "b"}},
+	}
+	err := Validate(u)
+	assert.Error(t, err)
+	assert.Equal(t, ValidationErrors{
+		{Field: "Name", Name: "name", Rule: "max", Param: "5"},
+	}, err)
+
+	u.Name = "maple"
+	assert.NoError(t, Validate(u))
+
+	u.Age = 10
+	u.Role = "guest"
+	u.Code = "12a4"
+	u.Email = "a"
+	u.Website = "example.com"
+	u.Confirm = "1234"
+	u.End = now.Add(-time.Hour)
+	u.Tags = []string{"a", "b", "c"}
+	u.Address.City = ""
+	u.Addresses = append(u.Addresses, testAddress{})
+
+	errs, ok := Validate(u).(ValidationErrors)
+	assert.True(t, ok)
+	rules := make(map[string]string)
+	for _, e := range errs {
+		rules[e.Name] = e.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"age":               "min",
+		"role":              "oneof",
+		"code":              "regexp",
+		"email":             "email",
+		"website":           "url",
+		"confirm":           "eqfield",
+		"end":               "gtfield",
+		"tags":              "max",
+		"address.city":      "required",
+		"addresses[1].city": "required",
+	}, rules)
+	assert.Equal(t, "Addresses[1].City", errs[len(errs)-1].Field)
+}
+
+func TestValidateSlice(t *testing.T) {
+	value := &struct {
+		Data   []byte          `validate:"required"`
+		Groups [][]testAddress `json:"groups"`
+	}{
+		Data:   make([]byte, 5<<20),
+		Groups: [][]testAddress{{{City: "a"}, {}}},
+	}
+	errs, ok := Validate(value).(ValidationErrors)
+	assert.True(t, ok)
+	assert.Equal(t, ValidationErrors{
+		{Field: "Groups[0][1].City", Name: "groups[0][1].city", Rule: "required"},
+	}, errs)
+
+	// slice of bytes is never walked
+	value.Groups = nil
+	allocs := testing.AllocsPerRun(10, func() {
+		assert.NoError(t, Validate(value))
+	})
+	assert.Less(t, allocs, float64(10))
+}
+
+func TestValidateInvalidRule(t *testing.T) {
+	for _, value := range []interface{}{
+		&struct {
+			Name string `validate:"min=abc"`
+		}{},
+		&struct {
+			Name string `validate:"required,unknown"`
+		}{},
+		&struct {
+			Name string `validate:"regexp=^[a-z"`
+		}{},
+		&struct {
+			Name string `validate:"eqfield=Other"`
+		}{},
+		[]struct {
+			Address testAddress
+			Age     int `validate:"max="`
+		}{{}},
+	} {
+		assert.NotPanics(t, func() {
+			err := Validate(value)
+			assert.Error(t, err)
+			_, ok := err.(ValidationErrors)
+			assert.False(t, ok, err.Error())
+		})
+	}
+}
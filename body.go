@@ -0,0 +1,107 @@
+package forest
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/honmaple/forest/binder"
+)
+
+// Body is a re-readable request body, which is written to a temp file
+// if the size is larger than the memory limit
+type Body struct {
+	buf    []byte
+	file   *os.File
+	size   int64
+	reader io.ReadSeeker
+}
+
+// NewBody reads all of r, limit <= 0 means no limit and binder.ErrBodyTooLarge
+// is returned if the size of r is larger than limit
+func NewBody(r io.Reader, memory, limit int64, dir string) (*Body, error) {
+	if limit > 0 && memory > limit {
+		memory = limit
+	}
+	buf, err := io.ReadAll(io.LimitReader(r, memory+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(buf)) <= memory {
+		return &Body{buf: buf, size: int64(len(buf)), reader: bytes.NewReader(buf)}, nil
+	}
+	// don't write the body to temp file if it's already too large
+	if limit > 0 && int64(len(buf)) > limit {
+		return nil, binder.ErrBodyTooLarge
+	}
+
+	file, err := os.CreateTemp(dir, "forest-body-")
+	if err != nil {
+		return nil, err
+	}
+	body := &Body{file: file, reader: file}
+	if _, err := file.Write(buf); err != nil {
+		body.Remove()
+		return nil, err
+	}
+	var n int64
+	if limit > 0 {
+		n, err = io.Copy(file, io.LimitReader(r, limit-int64(len(buf))+1))
+	} else {
+		n, err = io.Copy(file, r)
+	}
+	if err != nil {
+		body.Remove()
+		return nil, err
+	}
+	body.size = int64(len(buf)) + n
+	if limit > 0 && body.size > limit {
+		body.Remove()
+		return nil, binder.ErrBodyTooLarge
+	}
+	if err := body.Rewind(); err != nil {
+		body.Remove()
+		return nil, err
+	}
+	return body, nil
+}
+
+func (b *Body) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+// Close does nothing, so that the body could be read again after Rewind
+func (b *Body) Close() error {
+	return nil
+}
+
+func (b *Body) Size() int64 {
+	return b.size
+}
+
+// Rewind seeks to the start of body
+func (b *Body) Rewind() error {
+	_, err := b.reader.Seek(0, io.SeekStart)
+	return err
+}
+
+// Bytes returns all of body without changing the read offset
+func (b *Body) Bytes() ([]byte, error) {
+	if b.file == nil {
+		return b.buf, nil
+	}
+	buf := make([]byte, b.size)
+	if _, err := b.file.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Remove removes the temp file if the body is written to disk
+func (b *Body) Remove() error {
+	if b.file == nil {
+		return nil
+	}
+	b.file.Close()
+	return os.Remove(b.file.Name())
+}
@@ -0,0 +1,129 @@
+package forest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/forest/binder"
+	"github.com/honmaple/forest/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBody(t *testing.T) {
+	body, err := NewBody(strings.NewReader("hello"), 10, 0, "")
+	assert.Nil(t, err)
+	assert.Nil(t, body.file)
+	assert.Equal(t, int64(5), body.Size())
+
+	b, err := io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(b))
+	assert.Nil(t, body.Rewind())
+	b, err = io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	dir := t.TempDir()
+	body, err = NewBody(strings.NewReader("hello world"), 5, 20, dir)
+	assert.Nil(t, err)
+	assert.NotNil(t, body.file)
+	assert.Equal(t, int64(11), body.Size())
+
+	b, err = body.Bytes()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", string(b))
+	b, err = io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", string(b))
+
+	name := body.file.Name()
+	assert.Nil(t, body.Remove())
+	_, err = os.Stat(name)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = NewBody(strings.NewReader("hello world"), 5, 10, dir)
+	assert.True(t, errors.Is(err, binder.ErrBodyTooLarge))
+	_, err = NewBody(strings.NewReader("hello world"), 20, 10, dir)
+	assert.True(t, errors.Is(err, binder.ErrBodyTooLarge))
+	// oversize body read into memory is never written to temp file
+	_, err = NewBody(strings.NewReader("hello world"), 10, 10, dir)
+	assert.True(t, errors.Is(err, binder.ErrBodyTooLarge))
+
+	files, _ := os.ReadDir(dir)
+	assert.Empty(t, files)
+}
+
+func TestContextBody(t *testing.T) {
+	type request struct {
+		Name string `json:"name"`
+	}
+	router := New()
+	router.POST("/", func(c Context) error {
+		raw, err := c.Body()
+		if err != nil {
+			return err
+		}
+		var (
+			r    request
+			text string
+		)
+		if err := c.Bind(&r); err != nil {
+			return err
+		}
+		if err := c.BindWith(&text, binder.Text); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%s %s %s", raw, r.Name, text)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"forest"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"name":"forest"} forest {"name":"forest"}`, rec.Body.String())
+
+	// the original body is closed after reading
+	origin := &testCloseBody{Reader: strings.NewReader(`{"name":"forest"}`)}
+	req = httptest.NewRequest(http.MethodPost, "/", origin)
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, origin.closed)
+
+	size := binder.DefaultMaxBodySize
+	binder.DefaultMaxBodySize = 10
+	defer func() { binder.DefaultMaxBodySize = size }()
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"forest"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// body with too large Content-Length is rejected before reading
+	reader := strings.NewReader(`{"name":"forest"}`)
+	req = httptest.NewRequest(http.MethodPost, "/", &testCloseBody{Reader: reader})
+	req.ContentLength = int64(reader.Len())
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, int64(len(`{"name":"forest"}`)), int64(reader.Len()))
+}
+
+type testCloseBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *testCloseBody) Close() error {
+	b.closed = true
+	return nil
+}
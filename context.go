@@ -1,11 +1,17 @@
 package forest
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/honmaple/forest/binder"
 	"github.com/honmaple/forest/render"
@@ -19,9 +25,16 @@ type Context interface {
 	Route() *Route
 	Request() *http.Request
 	Response() *Response
+	Body() ([]byte, error)
+
+	RealIP() string
+	Scheme() string
+	Host() string
 
 	Next() error
 	NextWith(Context) error
+	Copy() Context
+	OnFinish(func(Context))
 
 	Get(string) interface{}
 	Set(string, interface{})
@@ -31,6 +44,9 @@ type Context interface {
 
 	FormParam(string, ...string) string
 	FormParams() (url.Values, error)
+	FormFile(string) (*multipart.FileHeader, error)
+	MultipartForm() (*multipart.Form, error)
+	SaveUploadedFile(*multipart.FileHeader, string) error
 
 	QueryParam(string, ...string) string
 	QueryParams() url.Values
@@ -38,8 +54,13 @@ type Context interface {
 	Cookie(string, ...*http.Cookie) (*http.Cookie, error)
 	Cookies() []*http.Cookie
 	SetCookie(*http.Cookie)
+	SignedCookie(string) (*http.Cookie, error)
+	SetSignedCookie(*http.Cookie) error
+	EncryptedCookie(string) (*http.Cookie, error)
+	SetEncryptedCookie(*http.Cookie) error
 
 	Bind(interface{}) error
+	BindAll(interface{}) error
 	BindWith(interface{}, binder.Binder) error
 	BindParams(interface{}) error
 	BindHeader(interface{}) error
@@ -53,16 +74,27 @@ type Context interface {
 	Blob(int, string, []byte) error
 	Render(int, string, interface{}) error
 	RenderWith(int, render.Renderer) error
+	Stream(int, string, io.Reader) error
 
 	File(string) error
 	FileFromFS(string, http.FileSystem) error
+	Attachment(string, string) error
+	Inline(string, string) error
+	ServeContent(string, time.Time, io.ReadSeeker) error
 
 	URL(string, ...interface{}) string
 	Status(int) error
 	Redirect(int, string, ...interface{}) error
+	Forward(string, ...interface{}) error
 }
 
+const maxForwards = 10
+
+var errCopiedContext = errors.New("forest: can't write response with copied context")
+
 type context struct {
+	ctx       Context
+	forest    *Forest
 	response  *Response
 	request   *http.Request
 	params    *contextParams
@@ -71,6 +103,8 @@ type context struct {
 	query     url.Values
 	route     *Route
 	index     int
+	forwards  int
+	finishes  []func(Context)
 }
 
 func (c *context) Forest() *Forest {
@@ -89,6 +123,53 @@ func (c *context) Response() *Response {
 	return c.response
 }
 
+// Body returns the raw request body, the body is buffered in memory
+// if it's not buffered by middleware, so that it could be bound again
+func (c *context) Body() ([]byte, error) {
+	if c.request.Body == nil || c.request.Body == http.NoBody {
+		return nil, nil
+	}
+	body, ok := c.request.Body.(*Body)
+	if !ok {
+		if c.request.ContentLength > binder.DefaultMaxBodySize {
+			return nil, NewError(http.StatusRequestEntityTooLarge)
+		}
+		var err error
+		// the original body has been read into memory, close it as BodyBuffer does
+		origin := c.request.Body
+		body, err = NewBody(origin, binder.DefaultMaxBodySize, binder.DefaultMaxBodySize, "")
+		origin.Close()
+		if err != nil {
+			if errors.Is(err, binder.ErrBodyTooLarge) {
+				return nil, NewError(http.StatusRequestEntityTooLarge)
+			}
+			return nil, err
+		}
+		c.request.Body = body
+	}
+	return body.Bytes()
+}
+
+// rewindBody makes buffered body could be bound with multi binders
+func (c *context) rewindBody() error {
+	if body, ok := c.request.Body.(*Body); ok {
+		return body.Rewind()
+	}
+	return nil
+}
+
+func (c *context) RealIP() string {
+	return c.forest.realIP(c.request)
+}
+
+func (c *context) Scheme() string {
+	return c.forest.scheme(c.request)
+}
+
+func (c *context) Host() string {
+	return c.forest.host(c.request)
+}
+
 func (c *context) Set(key string, value interface{}) {
 	c.storeLock.Lock()
 	defer c.storeLock.Unlock()
@@ -143,6 +224,45 @@ func (c *context) FormParams() (url.Values, error) {
 	return c.request.Form, nil
 }
 
+func (c *context) FormFile(name string) (*multipart.FileHeader, error) {
+	if err := binder.ParseForm(c.request, 0); err != nil {
+		return nil, err
+	}
+	f, fh, err := c.request.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return fh, nil
+}
+
+func (c *context) MultipartForm() (*multipart.Form, error) {
+	if err := binder.ParseForm(c.request, 0); err != nil {
+		return nil, err
+	}
+	return c.request.MultipartForm, nil
+}
+
+func (c *context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, src)
+	return err
+}
+
 func (c *context) QueryParam(key string, defaults ...string) string {
 	v := c.QueryParams().Get(key)
 	if v == "" && len(defaults) > 0 {
@@ -177,11 +297,94 @@ func (c *context) SetCookie(cookie *http.Cookie) {
 	http.SetCookie(c.response, cookie)
 }
 
+func (c *context) cookieCodec() *CookieCodec {
+	if c.forest == nil {
+		return nil
+	}
+	return c.forest.cookieCodec
+}
+
+func (c *context) SignedCookie(name string) (*http.Cookie, error) {
+	cookie, err := c.request.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.cookieCodec().Verify(name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	cookie.Value = value
+	return cookie, nil
+}
+
+func (c *context) SetSignedCookie(cookie *http.Cookie) error {
+	value, err := c.cookieCodec().Sign(cookie.Name, cookie.Value, cookieExpires(cookie))
+	if err != nil {
+		return err
+	}
+	signed := *cookie
+	signed.Value = value
+	c.SetCookie(&signed)
+	return nil
+}
+
+func (c *context) EncryptedCookie(name string) (*http.Cookie, error) {
+	cookie, err := c.request.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := c.cookieCodec().Decrypt(name, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	cookie.Value = value
+	return cookie, nil
+}
+
+func (c *context) SetEncryptedCookie(cookie *http.Cookie) error {
+	value, err := c.cookieCodec().Encrypt(cookie.Name, cookie.Value, cookieExpires(cookie))
+	if err != nil {
+		return err
+	}
+	encrypted := *cookie
+	encrypted.Value = value
+	c.SetCookie(&encrypted)
+	return nil
+}
+
 func (c *context) Bind(data interface{}) error {
-	return binder.Bind(c.request, data)
+	if err := c.rewindBody(); err != nil {
+		return err
+	}
+	if err := binder.Bind(c.request, data); err != nil {
+		return err
+	}
+	return c.validate(data)
+}
+
+// BindAll binds header, cookie, query, body and params with the same struct,
+// params has the highest precedence and header has the lowest
+func (c *context) BindAll(data interface{}) error {
+	if err := c.rewindBody(); err != nil {
+		return err
+	}
+	if err := binder.BindAll(c.request, c.Params(), data); err != nil {
+		return err
+	}
+	return c.validate(data)
+}
+
+func (c *context) validate(data interface{}) error {
+	if c.forest == nil || c.forest.validator == nil {
+		return nil
+	}
+	return c.forest.validator.Validate(data)
 }
 
 func (c *context) BindWith(data interface{}, b binder.Binder) error {
+	if err := c.rewindBody(); err != nil {
+		return err
+	}
 	return b.Bind(c.request, data)
 }
 
@@ -231,6 +434,10 @@ func (c *context) HTML(code int, data string) error {
 	return render.HTML(c.response, code, data)
 }
 
+func (c *context) Stream(code int, contentType string, r io.Reader) error {
+	return render.Stream(c.response, code, contentType, r)
+}
+
 func (c *context) Status(code int) error {
 	c.response.WriteHeader(code)
 	return nil
@@ -252,6 +459,69 @@ func (c *context) Redirect(code int, url string, args ...interface{}) error {
 	return nil
 }
 
+// Forward runs the handlers of named route with the same response
+func (c *context) Forward(name string, args ...interface{}) error {
+	e := c.forest
+	if e == nil {
+		e = c.route.Forest()
+	}
+	route := e.Route(name)
+	if route == nil {
+		return NewError(http.StatusInternalServerError, "forward route not found: "+name)
+	}
+	if c.forwards >= maxForwards {
+		return NewError(http.StatusLoopDetected, "too many forwards")
+	}
+
+	var (
+		oldRoute   = c.route
+		oldIndex   = c.index
+		oldQuery   = c.query
+		oldRequest = c.request
+		oldPIndex  = c.params.pindex
+		oldParams  = make([]string, len(c.params.pvalues))
+	)
+	copy(oldParams, c.params.pvalues)
+
+	// args are escaped like redirect, so that "/", "?" or "%" in args can't change the route
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		wildcard := i < len(route.pnames) && route.pnames[i].wildcard(route.path)
+		escaped[i] = escapePath(fmt.Sprintf("%v", arg), wildcard)
+	}
+	rawPath := route.URL(escaped...)
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return NewError(http.StatusInternalServerError, err.Error())
+	}
+
+	req := c.request.Clone(c.request.Context())
+	req.URL.Path = path
+	req.URL.RawPath = ""
+	if path != rawPath {
+		req.URL.RawPath = rawPath
+	}
+
+	// forwards is the nesting depth, not the total count of forwards
+	c.forwards++
+	defer func() {
+		c.forwards--
+		c.route = oldRoute
+		c.index = oldIndex
+		c.query = oldQuery
+		c.request = oldRequest
+		c.params.pindex = oldPIndex
+		copy(c.params.pvalues, oldParams)
+	}()
+
+	c.params.reset(0)
+	c.request = req
+	c.query = nil
+	c.index = -1
+	c.route = e.findRoute(route.Host(), route.Method(), rawPath, c.params)
+	return c.Next()
+}
+
 func (c *context) File(file string) (err error) {
 	return c.FileFromFS(filepath.Base(file), http.Dir(filepath.Dir(file)))
 }
@@ -298,12 +568,44 @@ func (c *context) FileFromFS(file string, fs http.FileSystem) error {
 	return nil
 }
 
+func (c *context) Attachment(file string, name string) error {
+	return c.contentDisposition(file, name, "attachment")
+}
+
+func (c *context) Inline(file string, name string) error {
+	return c.contentDisposition(file, name, "inline")
+}
+
+// contentDisposition sets Content-Disposition only if the file exists
+func (c *context) contentDisposition(file, name, dtype string) error {
+	fi, err := os.Stat(file)
+	if err != nil || fi.IsDir() {
+		return NotFoundHandler(c)
+	}
+	if name == "" {
+		name = filepath.Base(file)
+	}
+	c.response.Header().Set(render.ContentDispositionHeader, render.ContentDisposition(dtype, name))
+	return c.File(file)
+}
+
+// ServeContent serves content from reader with range and conditional requests support,
+// Content-Disposition will be set to inline with name if it's not set before
+func (c *context) ServeContent(name string, modtime time.Time, content io.ReadSeeker) error {
+	header := c.response.Header()
+	if name != "" && header.Get(render.ContentDispositionHeader) == "" {
+		header.Set(render.ContentDispositionHeader, render.ContentDisposition("inline", name))
+	}
+	http.ServeContent(c.response, c.request, name, modtime, content)
+	return nil
+}
+
 func (c *context) Logger() Logger {
 	return c.route.Logger()
 }
 
 func (c *context) Next() error {
-	return c.NextWith(c)
+	return c.NextWith(c.ctx)
 }
 
 func (c *context) NextWith(ctx Context) (err error) {
@@ -313,20 +615,100 @@ func (c *context) NextWith(ctx Context) (err error) {
 	}
 	if err != nil {
 		c.route.ErrorHandle(err, ctx)
+		if c.forest != nil {
+			for _, hook := range c.forest.onError {
+				hook(err, ctx)
+			}
+		}
 		return nil
 	}
 	return
 }
 
+// OnFinish registers callback which is called in LIFO order after the request is finished, even if panic.
+// A panic in one callback is recovered and logged, the remaining callbacks still run
+func (c *context) OnFinish(fn func(Context)) {
+	c.finishes = append(c.finishes, fn)
+}
+
+func (c *context) finish() {
+	for i := len(c.finishes) - 1; i >= 0; i-- {
+		c.runFinish(c.finishes[i])
+	}
+}
+
+func (c *context) runFinish(fn func(Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.forest.Logger.Errorf("forest: OnFinish callback panic: %v", r)
+		}
+	}()
+	fn(c.ctx)
+}
+
+// Copy returns a read-only copy of context that can be safely used outside
+// the request, such as in goroutine, writing response with the copy returns error.
+// The copy is wrapped by ContextFactory again, so custom fields are not copied
+func (c *context) Copy() Context {
+	cp := &context{
+		forest:   c.forest,
+		request:  c.request.Clone(c.request.Context()),
+		response: NewResponse(&copiedResponseWriter{header: c.response.Header().Clone()}),
+		params: &contextParams{
+			pindex:  c.params.pindex,
+			pvalues: make([]string, len(c.params.pvalues)),
+		},
+		route: c.route,
+		index: c.index,
+	}
+	cp.ctx = cp
+	if c.forest != nil && c.forest.contextFactory != nil {
+		cp.ctx = c.forest.contextFactory(cp)
+	}
+	copy(cp.params.pvalues, c.params.pvalues)
+	if c.route != nil {
+		cp.index = len(c.route.handlers)
+	}
+
+	c.storeLock.RLock()
+	if c.store != nil {
+		cp.store = make(map[string]interface{}, len(c.store))
+		for k, v := range c.store {
+			cp.store[k] = v
+		}
+	}
+	c.storeLock.RUnlock()
+	return cp.ctx
+}
+
 func (c *context) reset(r *http.Request, w http.ResponseWriter) {
 	c.response.reset(w)
 	c.request = r
 	c.store = nil
 	c.query = nil
 	c.index = -1
+	c.forwards = 0
+	c.finishes = nil
 	c.params.reset(0)
+	if ctx, ok := c.ctx.(interface{ Reset() }); ok {
+		ctx.Reset()
+	}
+}
+
+type copiedResponseWriter struct {
+	header http.Header
+}
+
+func (w *copiedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *copiedResponseWriter) Write([]byte) (int, error) {
+	return 0, errCopiedContext
 }
 
+func (w *copiedResponseWriter) WriteHeader(int) {}
+
 type contextParams struct {
 	pindex  int
 	pvalues []string
@@ -341,6 +723,7 @@ func (m *contextParams) reset(pindex int) {
 
 func NewContext(r *http.Request, w http.ResponseWriter) Context {
 	c := &context{response: NewResponse(w), params: &contextParams{}}
+	c.ctx = c
 	c.reset(r, w)
 	return c
 }
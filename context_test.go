@@ -1,12 +1,23 @@
 package forest
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/honmaple/forest/binder"
 	"github.com/honmaple/forest/render"
 	"github.com/stretchr/testify/assert"
 )
@@ -86,3 +97,433 @@ func TestContextBindParam(t *testing.T) {
 	assert.Equal(t, dst["var2"], "2")
 	assert.Equal(t, dst["var3"], "3")
 }
+
+func TestContextAttachment(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := NewContext(req, rec)
+	err := c.Attachment("context.go", "文件.go")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="__.go"; filename*=UTF-8''%E6%96%87%E4%BB%B6.go`, rec.Header().Get("Content-Disposition"))
+
+	rec = httptest.NewRecorder()
+	c = NewContext(req, rec)
+	err = c.Inline("context.go", "")
+	assert.NoError(t, err)
+	assert.Equal(t, `inline; filename="context.go"`, rec.Header().Get("Content-Disposition"))
+
+	rec = httptest.NewRecorder()
+	c = NewContext(req, rec)
+	err = c.Attachment("nope.txt", "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "", rec.Header().Get("Content-Disposition"))
+}
+
+func TestContextServeContent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=2-4")
+	rec := httptest.NewRecorder()
+	c := NewContext(req, rec)
+	err := c.ServeContent("export.txt", time.Now(), strings.NewReader(testString))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "st ", rec.Body.String())
+	assert.Equal(t, `inline; filename="export.txt"`, rec.Header().Get("Content-Disposition"))
+}
+
+func TestContextStream(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := NewContext(req, rec)
+	err := c.Stream(http.StatusCreated, render.ContentTypeOctetStream, strings.NewReader(testString))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, render.ContentTypeOctetStream, rec.Header().Get("Content-Type"))
+	assert.Equal(t, testString, rec.Body.String())
+}
+
+func TestContextFormFile(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("name", "forest")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		w, err := mw.CreateFormFile("files", name)
+		assert.NoError(t, err)
+		w.Write([]byte(testString))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(render.ContentType, mw.FormDataContentType())
+	c := NewContext(req, nil)
+
+	fh, err := c.FormFile("files")
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", fh.Filename)
+
+	dst := filepath.Join(t.TempDir(), "upload", fh.Filename)
+	assert.NoError(t, c.SaveUploadedFile(fh, dst))
+	b, err := os.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, testString, string(b))
+
+	type upload struct {
+		Name  string                  `form:"name"`
+		File  *multipart.FileHeader   `form:"files"`
+		Files []*multipart.FileHeader `form:"files"`
+	}
+	u := new(upload)
+	assert.NoError(t, c.Bind(u))
+	assert.Equal(t, "forest", u.Name)
+	assert.Equal(t, "a.txt", u.File.Filename)
+	assert.Len(t, u.Files, 2)
+}
+
+type testContext struct {
+	Context
+	value string
+}
+
+func (c *testContext) Reset() {
+	c.value = ""
+}
+
+func TestContextFactory(t *testing.T) {
+	router := New(ContextFactory(func(c Context) Context {
+		return &testContext{Context: c}
+	}))
+	m := func(c Context) error {
+		ctx, ok := c.(*testContext)
+		assert.True(t, ok)
+		assert.Equal(t, "", ctx.value)
+		ctx.value = c.Param("var")
+		return c.Next()
+	}
+	router.GET("/{var}", m, func(c Context) error {
+		cp, ok := c.Copy().(*testContext)
+		assert.True(t, ok)
+		assert.Equal(t, c.Param("var"), cp.Param("var"))
+		return c.String(http.StatusOK, c.(*testContext).value)
+	})
+
+	for _, v := range []string{"1", "2"} {
+		code, body := testRequest(http.MethodGet, "/"+v, router)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, v, body)
+	}
+
+	// contexts created by NewContext have no forest
+	c := NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.NotPanics(t, func() {
+		cp := c.Copy()
+		assert.Equal(t, "/", cp.Request().URL.Path)
+	})
+}
+
+func TestContextSignedCookie(t *testing.T) {
+	oldKey, newKey := []byte("old-key"), []byte("new-key")
+	blockKey := []byte("1234567890123456")
+
+	old := New(SignedCookieKeys(oldKey), EncryptedCookieKeys(blockKey))
+	rec := httptest.NewRecorder()
+	c := old.NewContext(nil, nil)
+	c.reset(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	assert.NoError(t, c.SetSignedCookie(&http.Cookie{Name: "signed", Value: "forest"}))
+	assert.NoError(t, c.SetEncryptedCookie(&http.Cookie{Name: "encrypted", Value: "forest"}))
+	assert.NoError(t, c.SetSignedCookie(&http.Cookie{Name: "expired", Value: "forest", Expires: time.Now().Add(-time.Hour)}))
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 3)
+	assert.NotEqual(t, "forest", cookies[0].Value)
+	assert.NotEqual(t, "forest", cookies[1].Value)
+
+	router := New(SignedCookieKeys(newKey, oldKey), EncryptedCookieKeys(blockKey))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	req.AddCookie(&http.Cookie{Name: "tampered", Value: cookies[0].Value})
+	c = router.NewContext(nil, nil)
+	c.reset(req, httptest.NewRecorder())
+
+	cookie, err := c.SignedCookie("signed")
+	assert.NoError(t, err)
+	assert.Equal(t, "forest", cookie.Value)
+
+	cookie, err = c.EncryptedCookie("encrypted")
+	assert.NoError(t, err)
+	assert.Equal(t, "forest", cookie.Value)
+
+	_, err = c.SignedCookie("expired")
+	assert.Equal(t, ErrExpiredCookie, err)
+
+	_, err = c.SignedCookie("tampered")
+	assert.Equal(t, ErrInvalidCookie, err)
+
+	_, err = c.EncryptedCookie("signed")
+	assert.Equal(t, ErrInvalidCookie, err)
+}
+
+func TestContextHooks(t *testing.T) {
+	var result []string
+
+	router := New()
+	router.OnRequest(func(c Context) {
+		result = append(result, "request")
+	})
+	router.OnResponse(func(c Context) {
+		result = append(result, "response")
+	})
+	router.OnError(func(err error, c Context) {
+		result = append(result, "error")
+	})
+	router.GET("/", func(c Context) error {
+		c.OnFinish(func(Context) {
+			result = append(result, "finish1")
+		})
+		c.OnFinish(func(Context) {
+			result = append(result, "finish2")
+		})
+		return NewError(400)
+	})
+	router.GET("/panic", func(c Context) error {
+		c.OnFinish(func(Context) {
+			result = append(result, "finish")
+		})
+		panic("panic")
+	})
+	router.GET("/finish-panic", func(c Context) error {
+		c.OnFinish(func(Context) {
+			result = append(result, "finish1")
+		})
+		c.OnFinish(func(Context) {
+			panic("finish panic")
+		})
+		c.OnFinish(func(Context) {
+			result = append(result, "finish3")
+		})
+		return c.String(http.StatusOK, "ok")
+	})
+
+	code, _ := testRequest(http.MethodGet, "/", router)
+	assert.Equal(t, 400, code)
+	assert.Equal(t, []string{"request", "error", "response", "finish2", "finish1"}, result)
+
+	result = nil
+	code, _ = testRequest(http.MethodGet, "/404", router)
+	assert.Equal(t, 404, code)
+	assert.Equal(t, []string{"request", "response"}, result)
+
+	result = nil
+	assert.Panics(t, func() {
+		testRequest(http.MethodGet, "/panic", router)
+	})
+	assert.Equal(t, []string{"request", "finish"}, result)
+
+	result = nil
+	router.Logger = &logger{log.New(io.Discard, "", 0)}
+	assert.NotPanics(t, func() {
+		code, _ = testRequest(http.MethodGet, "/finish-panic", router)
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"request", "response", "finish3", "finish1"}, result)
+}
+
+func TestContextCopy(t *testing.T) {
+	var (
+		wg     sync.WaitGroup
+		router = New()
+	)
+	router.GET("/{var}", func(c Context) error {
+		c.Set("var", c.Param("var"))
+		cp := c.Copy()
+		route := c.Route()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Millisecond)
+			v := cp.Param("var")
+			assert.Equal(t, v, cp.Get("var"))
+			assert.Equal(t, "/"+v, cp.Request().URL.Path)
+			assert.Equal(t, route, cp.Route())
+			assert.NotNil(t, cp.Logger())
+			assert.Error(t, cp.String(http.StatusOK, v))
+		}()
+		return c.String(http.StatusOK, c.Param("var"))
+	})
+
+	for i := 0; i < 100; i++ {
+		v := strconv.Itoa(i)
+		code, body := testRequest(http.MethodGet, "/"+v, router)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, v, body)
+	}
+	wg.Wait()
+}
+
+func TestContextBindValidate(t *testing.T) {
+	type user struct {
+		Name string `json:"name" validate:"required"`
+	}
+	router := New(Validator(binder.DefaultValidator))
+	router.POST("/", func(c Context) error {
+		u := new(user)
+		if err := c.Bind(u); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, u.Name)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name": ""}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"message":"Unprocessable Entity","errors":[{"field":"Name","name":"name","rule":"required"}]}`, rec.Body.String())
+}
+
+func TestContextBindError(t *testing.T) {
+	type request struct {
+		Page int `query:"page"`
+		Age  int `json:"age"`
+	}
+	router := New()
+	router.POST("/", func(c Context) error {
+		r := new(request)
+		if err := c.BindAll(r); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, r)
+	})
+
+	code, body := testRequest(http.MethodPost, "/?page=x", router)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, `"field":"page"`)
+	assert.Contains(t, body, `"source":"query"`)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age": "18"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"field":"age"`)
+	assert.Contains(t, rec.Body.String(), `"source":"json"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`age=18`))
+	req.Header.Set(render.ContentType, "application/x-unknown")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	size := binder.DefaultMaxBodySize
+	binder.DefaultMaxBodySize = 10
+	defer func() { binder.DefaultMaxBodySize = size }()
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age": 18, "name": "forest"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestContextBindAll(t *testing.T) {
+	type request struct {
+		ID    int    `param:"id" json:"id"`
+		Page  int    `query:"page"`
+		Title string `json:"title" query:"title"`
+		Token string `header:"X-Token" cookie:"token"`
+	}
+	router := New()
+	router.Add("GET", "/posts/{id}", func(c Context) error {
+		r := new(request)
+		if err := c.BindAll(r); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, r)
+	})
+	router.Add("POST", "/posts/{id}", func(c Context) error {
+		r := new(request)
+		if err := c.BindAll(r); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, r)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/posts/1?page=2&title=query", nil)
+	req.Header.Set("X-Token", "header")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.JSONEq(t, `{"id":1,"Page":2,"title":"query","Token":"header"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/posts/1?page=2&title=query", strings.NewReader(`{"id":3,"title":"body"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	req.Header.Set("X-Token", "header")
+	req.AddCookie(&http.Cookie{Name: "token", Value: "cookie"})
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.JSONEq(t, `{"id":1,"Page":2,"title":"body","Token":"cookie"}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/posts/1", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.JSONEq(t, `{"id":1,"Page":0,"title":"","Token":""}`, rec.Body.String())
+}
+
+func TestContextForward(t *testing.T) {
+	router := New()
+	router.GET("/posts/{id}", func(c Context) error {
+		return c.String(200, "post "+c.Param("id"))
+	}).Named("post")
+	router.GET("/legacy/{id}", func(c Context) error {
+		return c.Forward("post", c.Param("id"))
+	})
+	router.GET("/files/{path:path}", func(c Context) error {
+		return c.String(200, "file "+c.Request().URL.Path)
+	}).Named("file")
+	router.GET("/escape", func(c Context) error {
+		return c.Forward("post", c.QueryParam("id"))
+	})
+	router.GET("/escape-file", func(c Context) error {
+		return c.Forward("file", c.QueryParam("path"))
+	})
+	router.GET("/loop", func(c Context) error {
+		return c.Forward("loop")
+	}).Named("loop")
+	router.GET("/missing", func(c Context) error {
+		return c.Forward("missing")
+	})
+	router.GET("/sequential/{id}", func(c Context) error {
+		for i := 0; i < maxForwards+2; i++ {
+			if err := c.Forward("post", i); err != nil {
+				return err
+			}
+		}
+		return c.String(200, " "+c.Param("id"))
+	})
+
+	code, body := testRequest(http.MethodGet, "/legacy/1", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "post 1", body)
+
+	// args are escaped, "/", "?" and "%" can't route to another handler
+	code, body = testRequest(http.MethodGet, "/escape?id="+url.QueryEscape("a/b?c%d"), router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "post a%2Fb%3Fc%25d", body)
+
+	code, body = testRequest(http.MethodGet, "/escape-file?path="+url.QueryEscape("a/b?c"), router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "file /files/a/b?c", body)
+
+	code, _ = testRequest(http.MethodGet, "/loop", router)
+	assert.Equal(t, http.StatusLoopDetected, code)
+
+	code, _ = testRequest(http.MethodGet, "/missing", router)
+	assert.Equal(t, http.StatusInternalServerError, code)
+
+	// sequential forwards are not loop, and params are restored after forwarding
+	code, body = testRequest(http.MethodGet, "/sequential/abc", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "post 0post 1post 2post 3post 4post 5post 6post 7post 8post 9post 10post 11 abc", body)
+}
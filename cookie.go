@@ -0,0 +1,157 @@
+package forest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrCookieKeys    = errors.New("forest: cookie keys are not set")
+	ErrInvalidCookie = errors.New("forest: invalid cookie value")
+	ErrExpiredCookie = errors.New("forest: expired cookie value")
+)
+
+// CookieCodec signs cookie value with HMAC-SHA256 or encrypts it with AES-GCM,
+// the first key is used to sign or encrypt, and all keys are used to verify
+// or decrypt, so that old keys can be kept for key rotation.
+type CookieCodec struct {
+	hashKeys [][]byte
+	aeads    []cipher.AEAD
+}
+
+func (s *CookieCodec) SetHashKeys(keys ...[]byte) {
+	s.hashKeys = keys
+}
+
+// SetBlockKeys sets AES keys, each key must be 16, 24 or 32 bytes
+func (s *CookieCodec) SetBlockKeys(keys ...[]byte) error {
+	aeads := make([]cipher.AEAD, len(keys))
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		if aeads[i], err = cipher.NewGCM(block); err != nil {
+			return err
+		}
+	}
+	s.aeads = aeads
+	return nil
+}
+
+func (s *CookieCodec) mac(key []byte, name string, b []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+func (s *CookieCodec) Sign(name, value string, expires time.Time) (string, error) {
+	if s == nil || len(s.hashKeys) == 0 {
+		return "", ErrCookieKeys
+	}
+	b := encodeCookieValue(value, expires)
+	b = append(b, s.mac(s.hashKeys[0], name, b)...)
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *CookieCodec) Verify(name, value string) (string, error) {
+	if s == nil || len(s.hashKeys) == 0 {
+		return "", ErrCookieKeys
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) < sha256.Size {
+		return "", ErrInvalidCookie
+	}
+	b, sum := b[:len(b)-sha256.Size], b[len(b)-sha256.Size:]
+	for _, key := range s.hashKeys {
+		if hmac.Equal(sum, s.mac(key, name, b)) {
+			return decodeCookieValue(b)
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+func (s *CookieCodec) Encrypt(name, value string, expires time.Time) (string, error) {
+	if s == nil || len(s.aeads) == 0 {
+		return "", ErrCookieKeys
+	}
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	b := aead.Seal(nonce, nonce, encodeCookieValue(value, expires), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *CookieCodec) Decrypt(name, value string) (string, error) {
+	if s == nil || len(s.aeads) == 0 {
+		return "", ErrCookieKeys
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, aead := range s.aeads {
+		size := aead.NonceSize()
+		if len(b) < size {
+			continue
+		}
+		if plain, err := aead.Open(nil, b[:size], b[size:], []byte(name)); err == nil {
+			return decodeCookieValue(plain)
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+func encodeCookieValue(value string, expires time.Time) []byte {
+	b := make([]byte, 8, 8+len(value)+sha256.Size)
+	if !expires.IsZero() {
+		binary.BigEndian.PutUint64(b, uint64(expires.Unix()))
+	}
+	return append(b, value...)
+}
+
+func decodeCookieValue(b []byte) (string, error) {
+	if len(b) < 8 {
+		return "", ErrInvalidCookie
+	}
+	if expires := int64(binary.BigEndian.Uint64(b)); expires > 0 && expires < time.Now().Unix() {
+		return "", ErrExpiredCookie
+	}
+	return string(b[8:]), nil
+}
+
+func cookieExpires(cookie *http.Cookie) time.Time {
+	if cookie.MaxAge > 0 {
+		return time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+	}
+	return cookie.Expires
+}
+
+// SignedCookieKeys sets HMAC keys of signed cookie, the old keys should be put at the end
+func SignedCookieKeys(keys ...[]byte) Option {
+	return func(e *Forest) {
+		e.cookieCodec.SetHashKeys(keys...)
+	}
+}
+
+// EncryptedCookieKeys sets AES keys of encrypted cookie, the old keys should be put at the end
+func EncryptedCookieKeys(keys ...[]byte) Option {
+	return func(e *Forest) {
+		if err := e.cookieCodec.SetBlockKeys(keys...); err != nil {
+			panic("forest: " + err.Error())
+		}
+	}
+}
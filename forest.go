@@ -2,7 +2,9 @@ package forest
 
 import (
 	stdcontext "context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"reflect"
@@ -10,6 +12,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+
+	"github.com/honmaple/forest/binder"
 )
 
 type (
@@ -28,6 +32,15 @@ type (
 		maxParam              int
 		debug                 bool
 		hostMatch             func(string, string) bool
+		contextFactory        func(Context) Context
+		trustedHops           int
+		trustedProxies        []*net.IPNet
+		trustedHeader         string
+		cookieCodec           *CookieCodec
+		onRequest             []func(Context)
+		onResponse            []func(Context)
+		onError               []ErrorHandlerFunc
+		validator             binder.StructValidator
 		Server                *http.Server
 	}
 	HandlerFunc      func(Context) error
@@ -52,6 +65,24 @@ var (
 		if err == nil {
 			return
 		}
+		if errs, ok := err.(binder.ValidationErrors); ok {
+			if resp := c.Response(); !resp.Written() {
+				c.JSON(http.StatusUnprocessableEntity, H{"message": http.StatusText(http.StatusUnprocessableEntity), "errors": errs})
+			}
+			return
+		}
+		if e, ok := err.(*binder.Error); ok {
+			code := http.StatusBadRequest
+			if errors.Is(e, binder.ErrBodyTooLarge) {
+				code = http.StatusRequestEntityTooLarge
+			} else if errors.Is(e, binder.ErrUnsupportedMediaType) {
+				code = http.StatusUnsupportedMediaType
+			}
+			if resp := c.Response(); !resp.Written() {
+				c.JSON(code, H{"message": e.Error(), "source": e.Source, "field": e.Name})
+			}
+			return
+		}
 		e, ok := err.(*Error)
 		if !ok {
 			e = ErrInternalServerError
@@ -108,6 +139,22 @@ func HostMatch(matcher func(string, string) bool) Option {
 	}
 }
 
+// ContextFactory wraps the pooled default context with custom context,
+// if custom context has Reset method, it will be called before each request
+func ContextFactory(factory func(Context) Context) Option {
+	return func(e *Forest) {
+		e.contextFactory = factory
+	}
+}
+
+// Validator enables validating struct after c.Bind, use binder.DefaultValidator
+// to validate with validate tag
+func Validator(v binder.StructValidator) Option {
+	return func(e *Forest) {
+		e.validator = v
+	}
+}
+
 func Middlewares(handlers ...HandlerFunc) Option {
 	return func(e *Forest) {
 		e.middlewares = handlers
@@ -116,8 +163,9 @@ func Middlewares(handlers ...HandlerFunc) Option {
 
 func New(opts ...Option) *Forest {
 	e := &Forest{
-		node:   &node{},
-		routes: make(map[string]*Route),
+		node:        &node{},
+		routes:      make(map[string]*Route),
+		cookieCodec: new(CookieCodec),
 	}
 	e.rootGroup = &Group{
 		forest:      e,
@@ -229,7 +277,7 @@ func (e *Forest) findRoute(host, method, path string, params *contextParams) *Ro
 
 func (e *Forest) Route(name string) *Route {
 	for _, route := range e.routes {
-		if route.Name == name {
+		if route.Name == name && route.canonical == nil {
 			return route
 		}
 	}
@@ -272,6 +320,24 @@ func (e *Forest) MethodNotAllowed(handlers ...HandlerFunc) *Route {
 	return e.methodNotAllowedRoute
 }
 
+// OnRequest registers hooks which are called before the handlers of every request
+func (e *Forest) OnRequest(hooks ...func(Context)) *Forest {
+	e.onRequest = append(e.onRequest, hooks...)
+	return e
+}
+
+// OnResponse registers hooks which are called after the response of every request is written
+func (e *Forest) OnResponse(hooks ...func(Context)) *Forest {
+	e.onResponse = append(e.onResponse, hooks...)
+	return e
+}
+
+// OnError registers hooks which are called after the error is handled
+func (e *Forest) OnError(hooks ...ErrorHandlerFunc) *Forest {
+	e.onError = append(e.onError, hooks...)
+	return e
+}
+
 func (e *Forest) Use(middlewares ...HandlerFunc) *Forest {
 	e.rootGroup.Use(middlewares...)
 	e.notFoundRoute.handlers = combineHandlers(e.middlewares, e.notFound)
@@ -289,11 +355,16 @@ func (e *Forest) MountGroup(child *Group, opts ...GroupOption) {
 
 func (e *Forest) NewContext(w http.ResponseWriter, r *http.Request) *context {
 	c := &context{
+		forest: e,
 		params: &contextParams{
 			pvalues: make([]string, e.maxParam),
 		},
 		response: NewResponse(w),
 	}
+	c.ctx = c
+	if e.contextFactory != nil {
+		c.ctx = e.contextFactory(c)
+	}
 	return c
 }
 
@@ -301,6 +372,7 @@ func (e *Forest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c := e.contextPool.Get().(*context)
 	c.reset(r, w)
 	defer e.contextPool.Put(c)
+	defer c.finish()
 
 	path := r.URL.RawPath
 	if path == "" {
@@ -308,7 +380,14 @@ func (e *Forest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	// pass []string is faster than *context than *([]string)
 	c.route = e.findRoute(r.Host, r.Method, path, c.params)
+	for _, hook := range e.onRequest {
+		hook(c.ctx)
+	}
 	c.Next()
+	c.response.finish()
+	for _, hook := range e.onResponse {
+		hook(c.ctx)
+	}
 }
 
 func (e *Forest) configure(addr string) error {
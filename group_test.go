@@ -203,3 +203,128 @@ func TestGroupRouteName(t *testing.T) {
 	// r4.Name = "r4.1"
 	// assert.Equal(t, router.Route("r4.1"), r4)
 }
+
+type testPostController struct{}
+
+func (testPostController) List(c Context) error {
+	return c.String(200, "list")
+}
+
+func (testPostController) Show(c Context) error {
+	return c.String(200, "show "+c.Param("id"))
+}
+
+func (testPostController) Delete(c Context) error {
+	return c.Status(204)
+}
+
+type testCommentController struct{}
+
+func (testCommentController) Show(c Context) error {
+	return c.String(200, "show "+c.Param("post_id")+" "+c.Param("id"))
+}
+
+func TestGroupResource(t *testing.T) {
+	router := New()
+	api := router.Group(WithPrefix("/api"), WithName("api"))
+	routes := api.Resource("/posts", testPostController{})
+	assert.Len(t, routes, 3)
+	api.Resource("/posts/{post_id}/comments", testCommentController{})
+
+	code, body := testRequest(http.MethodGet, "/api/posts", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "list", body)
+
+	code, body = testRequest(http.MethodGet, "/api/posts/1", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "show 1", body)
+
+	code, _ = testRequest(http.MethodDelete, "/api/posts/1", router)
+	assert.Equal(t, 204, code)
+
+	code, _ = testRequest(http.MethodPost, "/api/posts", router)
+	assert.Equal(t, 405, code)
+
+	code, body = testRequest(http.MethodGet, "/api/posts/1/comments/2", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "show 1 2", body)
+
+	assert.Equal(t, "/api/posts/1", router.URL("api.posts.show", 1))
+	assert.Equal(t, "/api/posts/1/comments/2", router.URL("api.posts.comments.show", 1, 2))
+
+	assert.Panics(t, func() {
+		api.Resource("/users", struct{}{})
+	})
+}
+
+func TestGroupRedirect(t *testing.T) {
+	router := New()
+	router.GET("/posts/{slug}", func(c Context) error {
+		return c.String(200, c.Param("slug"))
+	}).Named("post").Alias("/articles/{slug}", "/p/:slug")
+	router.Redirect("/blog/{slug}", "/posts/{slug}", http.StatusMovedPermanently)
+	router.Redirect("/old/{slug}", "post", http.StatusFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/blog/hello?page=1", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/posts/hello?page=1", rec.Header().Get("Location"))
+
+	req = httptest.NewRequest(http.MethodPost, "/old/hello", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/posts/hello", rec.Header().Get("Location"))
+
+	code, body := testRequest(http.MethodGet, "/articles/hello", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "hello", body)
+
+	code, body = testRequest(http.MethodGet, "/p/world", router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "world", body)
+
+	assert.Equal(t, "/posts/hello", router.URL("post", "hello"))
+
+	router.GET("/files/{path:path}", func(c Context) error {
+		return c.String(200, c.Param("path"))
+	}).Alias("/f/{path:path}").Named("file")
+	for _, route := range router.Routes() {
+		if route.Path() == "/f/{path:path}" {
+			assert.Equal(t, "file", route.Name)
+		}
+	}
+	assert.Equal(t, "/files/a", router.Route("file").URL("a"))
+
+	// alias must declare the same params as the canonical route
+	assert.Panics(t, func() {
+		router.GET("/users/{name}", func(c Context) error { return nil }).Alias("/u/{id}")
+	})
+	assert.Panics(t, func() {
+		router.GET("/tags/{name}", func(c Context) error { return nil }).Alias("/t")
+	})
+
+	router.Redirect("/go/{name}", "/posts/{name}", http.StatusFound)
+	router.Redirect("/dl/{path:path}", "/files/{path:path}", http.StatusFound)
+	router.Redirect("/open/{path:path}", "/{path:path}", http.StatusFound)
+	router.Redirect("/old-files/{path:path}", "file", http.StatusFound)
+	for _, c := range []struct {
+		path     string
+		code     int
+		location string
+	}{
+		{"/go/a%2Fb%3Fx", http.StatusFound, "/posts/a%2Fb%3Fx"},
+		{"/go/hello%20world", http.StatusFound, "/posts/hello%20world"},
+		{"/dl/a/b%3F/c", http.StatusFound, "/files/a/b%3F/c"},
+		{"/old-files/a/b", http.StatusFound, "/files/a/b"},
+		{"/open/evil.com/x", http.StatusFound, "/evil.com/x"},
+		{"/open//evil.com/x", http.StatusBadRequest, ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, c.code, rec.Code, c.path)
+		assert.Equal(t, c.location, rec.Header().Get("Location"), c.path)
+	}
+}
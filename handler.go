@@ -0,0 +1,110 @@
+package forest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/honmaple/forest/binder"
+	"github.com/honmaple/forest/render"
+)
+
+var (
+	contextType = reflect.TypeOf((*Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+type validator interface {
+	Validate() error
+}
+
+// TypedHandler converts func(Context, *Request) (*Response, error) to HandlerFunc,
+// the request is bound with c.BindAll and validated if it has Validate() error
+// method, and the response is rendered by Accept header.
+func TypedHandler(fn interface{}) HandlerFunc {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic("forest: typed handler must be a func")
+	}
+	if ft.NumIn() != 2 || ft.In(0) != contextType || ft.In(1).Kind() != reflect.Ptr {
+		panic("forest: typed handler must be func(forest.Context, *Request) (Response, error)")
+	}
+	if ft.NumOut() != 2 || ft.Out(1) != errorType {
+		panic("forest: typed handler must be func(forest.Context, *Request) (Response, error)")
+	}
+	reqType := ft.In(1).Elem()
+	return func(c Context) error {
+		req := reflect.New(reqType)
+		dst := req.Interface()
+		if err := c.BindAll(dst); err != nil {
+			switch err.(type) {
+			case binder.ValidationErrors, *binder.Error:
+				return err
+			}
+			return NewError(http.StatusBadRequest, err.Error())
+		}
+		if v, ok := dst.(validator); ok {
+			if err := v.Validate(); err != nil {
+				return NewError(http.StatusBadRequest, err.Error())
+			}
+		}
+		out := fv.Call([]reflect.Value{reflect.ValueOf(c), req})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return err
+		}
+		resp := out[0]
+		if (resp.Kind() == reflect.Ptr || resp.Kind() == reflect.Interface) && resp.IsNil() {
+			return c.Status(http.StatusNoContent)
+		}
+		return negotiate(c, http.StatusOK, resp.Interface())
+	}
+}
+
+// negotiate renders data with the registered render of Accept header, JSON is default
+func negotiate(c Context, code int, data interface{}) error {
+	var (
+		quality float64 = -1
+		format          = render.ContentTypeJSON
+	)
+	for _, accept := range strings.Split(c.Request().Header.Get("Accept"), ",") {
+		mediaType, q := accept, 1.0
+		if index := strings.IndexByte(accept, ';'); index > -1 {
+			mediaType = accept[:index]
+			for _, param := range strings.Split(accept[index+1:], ";") {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					q, _ = strconv.ParseFloat(param[2:], 64)
+				}
+			}
+		}
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if q <= 0 || q <= quality {
+			continue
+		}
+		switch mediaType {
+		case render.ContentTypeJSON, "*/*", "application/*":
+			quality, format = q, render.ContentTypeJSON
+		case render.ContentTypeXML, "text/xml":
+			quality, format = q, render.ContentTypeXML
+		default:
+			if _, ok := render.Lookup(mediaType); ok {
+				quality, format = q, mediaType
+			}
+		}
+	}
+	switch format {
+	case render.ContentTypeJSON:
+		return c.JSON(code, data)
+	case render.ContentTypeXML:
+		return c.XML(code, data)
+	default:
+		err := render.Render(c.Response(), code, format, data)
+		if errors.Is(err, render.ErrNotAcceptable) {
+			return NewError(http.StatusNotAcceptable)
+		}
+		return err
+	}
+}
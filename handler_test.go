@@ -0,0 +1,86 @@
+package forest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/forest/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	testTypedRequest struct {
+		ID   int    `param:"id"`
+		Name string `json:"name" query:"name"`
+	}
+	testTypedResponse struct {
+		ID   int    `json:"id" xml:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+)
+
+func (r *testTypedRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestTypedHandler(t *testing.T) {
+	router := New()
+	router.Add("GET", "/posts/{id:int}", TypedHandler(func(c Context, req *testTypedRequest) (*testTypedResponse, error) {
+		return &testTypedResponse{ID: req.ID, Name: req.Name}, nil
+	}))
+	router.Add("POST", "/posts/{id:int}", TypedHandler(func(c Context, req *testTypedRequest) (*testTypedResponse, error) {
+		if req.Name == "none" {
+			return nil, nil
+		}
+		return nil, NewError(http.StatusConflict)
+	}))
+
+	code, body := testRequest(http.MethodGet, "/posts/1?name=forest", router)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "{\"id\":1,\"name\":\"forest\"}\n", body)
+
+	code, _ = testRequest(http.MethodGet, "/posts/1", router)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	req := httptest.NewRequest(http.MethodGet, "/posts/1?name=forest", nil)
+	req.Header.Set("Accept", "application/json;q=0.8, application/xml")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, render.ContentTypeXMLCharsetUTF8, rec.Header().Get(render.ContentType))
+	assert.Equal(t, "<testTypedResponse><id>1</id><name>forest</name></testTypedResponse>", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/posts/1?name=forest", nil)
+	req.Header.Set("Accept", "application/xml;q=0, text/html")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, render.ContentTypeJSONCharsetUTF8, rec.Header().Get(render.ContentType))
+
+	req = httptest.NewRequest(http.MethodGet, "/posts/1?name=forest", nil)
+	req.Header.Set("Accept", "text/plain")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "forest")
+
+	req = httptest.NewRequest(http.MethodPost, "/posts/1", strings.NewReader(`{"name":"none"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/posts/1", strings.NewReader(`{"name":"forest"}`))
+	req.Header.Set(render.ContentType, render.ContentTypeJSON)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	assert.Panics(t, func() {
+		TypedHandler(func(c Context) error { return nil })
+	})
+}
@@ -0,0 +1,324 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/honmaple/forest"
+	"github.com/honmaple/forest/binder"
+	"github.com/honmaple/forest/render"
+)
+
+const (
+	Version = "2.0"
+
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeServerError    = -32000
+)
+
+type (
+	Request struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params,omitempty"`
+		ID      json.RawMessage `json:"id,omitempty"`
+	}
+	Response struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Result  json.RawMessage `json:"result,omitempty"`
+		Error   *Error          `json:"error,omitempty"`
+		ID      json.RawMessage `json:"id"`
+	}
+	Error struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Data    interface{} `json:"data,omitempty"`
+	}
+	Server struct {
+		// MaxBodySize limits the size of request body, 0 means binder.DefaultMaxBodySize and -1 means no limit
+		MaxBodySize int64
+		methods     map[string][]forest.HandlerFunc
+		middlewares []forest.HandlerFunc
+	}
+)
+
+var null = json.RawMessage("null")
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+func NewError(code int, message string, data ...interface{}) *Error {
+	e := &Error{Code: code, Message: message}
+	if len(data) > 0 {
+		e.Data = data[0]
+	}
+	return e
+}
+
+func toError(err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *forest.Error:
+		return &Error{Code: CodeServerError, Message: fmt.Sprint(e.Message), Data: e.Code}
+	default:
+		return &Error{Code: CodeInternalError, Message: err.Error()}
+	}
+}
+
+// notification reports whether request is a valid request object without id,
+// invalid request object is always replied with id null
+func (r *Request) notification() bool {
+	return len(r.ID) == 0 && r.JSONRPC == Version && r.Method != ""
+}
+
+// Use adds middlewares for all methods
+func (s *Server) Use(middlewares ...forest.HandlerFunc) *Server {
+	s.middlewares = append(s.middlewares, middlewares...)
+	return s
+}
+
+// Register adds method with handlers, the params can be decoded with c.Bind,
+// and the result is what handler writes with c.JSON or c.String
+func (s *Server) Register(method string, handlers ...forest.HandlerFunc) *Server {
+	if len(handlers) == 0 {
+		panic("jsonrpc: no handler found: " + method)
+	}
+	s.methods[method] = handlers
+	return s
+}
+
+func (s *Server) call(c forest.Context, req *Request) *Response {
+	resp := &Response{JSONRPC: Version, ID: req.ID}
+	if len(resp.ID) == 0 {
+		resp.ID = null
+	}
+	if req.JSONRPC != Version || req.Method == "" {
+		resp.Error = NewError(CodeInvalidRequest, "Invalid Request")
+		return resp
+	}
+	handlers, ok := s.methods[req.Method]
+	if !ok {
+		resp.Error = NewError(CodeMethodNotFound, "Method not found")
+		return resp
+	}
+
+	ctx := newContext(c, req, s.middlewares, handlers)
+	if err := ctx.run(); err != nil {
+		resp.Error = toError(err)
+		return resp
+	}
+	if w := ctx.writer; w.status >= 400 {
+		message := strings.TrimSpace(w.body.String())
+		if message == "" {
+			message = http.StatusText(w.status)
+		}
+		resp.Error = &Error{Code: CodeServerError, Message: message, Data: w.status}
+	} else {
+		resp.Result = w.result()
+	}
+	return resp
+}
+
+func (s *Server) Handler() forest.HandlerFunc {
+	return func(c forest.Context) error {
+		size := s.MaxBodySize
+		if size == 0 {
+			size = binder.DefaultMaxBodySize
+		}
+		var body io.Reader = c.Request().Body
+		if size > 0 {
+			if c.Request().ContentLength > size {
+				return forest.NewError(http.StatusRequestEntityTooLarge)
+			}
+			body = io.LimitReader(body, size+1)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		if size > 0 && int64(len(data)) > size {
+			return forest.NewError(http.StatusRequestEntityTooLarge)
+		}
+
+		var raw json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return c.JSON(http.StatusOK, &Response{
+				JSONRPC: Version,
+				Error:   NewError(CodeParseError, "Parse error"),
+				ID:      null,
+			})
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 || raw[0] != '[' {
+			req := new(Request)
+			if err := json.Unmarshal(raw, req); err != nil {
+				return c.JSON(http.StatusOK, &Response{
+					JSONRPC: Version,
+					Error:   NewError(CodeInvalidRequest, "Invalid Request"),
+					ID:      null,
+				})
+			}
+			resp := s.call(c, req)
+			if req.notification() {
+				return c.Status(http.StatusNoContent)
+			}
+			return c.JSON(http.StatusOK, resp)
+		}
+
+		reqs := make([]json.RawMessage, 0)
+		if err := json.Unmarshal(raw, &reqs); err != nil || len(reqs) == 0 {
+			return c.JSON(http.StatusOK, &Response{
+				JSONRPC: Version,
+				Error:   NewError(CodeInvalidRequest, "Invalid Request"),
+				ID:      null,
+			})
+		}
+		resps := make([]*Response, 0, len(reqs))
+		for _, r := range reqs {
+			req := new(Request)
+			if err := json.Unmarshal(r, req); err != nil {
+				resps = append(resps, &Response{
+					JSONRPC: Version,
+					Error:   NewError(CodeInvalidRequest, "Invalid Request"),
+					ID:      null,
+				})
+				continue
+			}
+			// notification has no response, even if it fails
+			if resp := s.call(c, req); !req.notification() {
+				resps = append(resps, resp)
+			}
+		}
+		if len(resps) == 0 {
+			return c.Status(http.StatusNoContent)
+		}
+		return c.JSON(http.StatusOK, resps)
+	}
+}
+
+// Mount registers the server on group path with POST method
+func (s *Server) Mount(g *forest.Group, path string, middlewares ...forest.HandlerFunc) *forest.Route {
+	handlers := make([]forest.HandlerFunc, 0, len(middlewares)+1)
+	handlers = append(handlers, middlewares...)
+	handlers = append(handlers, s.Handler())
+	return g.POST(path, handlers...)
+}
+
+func New(middlewares ...forest.HandlerFunc) *Server {
+	return &Server{
+		methods:     make(map[string][]forest.HandlerFunc),
+		middlewares: middlewares,
+	}
+}
+
+type (
+	// context runs method handlers with the params as request body,
+	// and the response is captured in memory instead of real response
+	context struct {
+		forest.Context
+		req      *Request
+		writer   *responseWriter
+		handlers []forest.HandlerFunc
+		index    int
+	}
+	responseWriter struct {
+		header http.Header
+		status int
+		body   bytes.Buffer
+	}
+)
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *responseWriter) result() json.RawMessage {
+	b := bytes.TrimSpace(w.body.Bytes())
+	if len(b) == 0 {
+		return null
+	}
+	if strings.HasPrefix(w.header.Get(render.ContentType), render.ContentTypeJSON) && json.Valid(b) {
+		return json.RawMessage(b)
+	}
+	result, _ := json.Marshal(string(b))
+	return result
+}
+
+// run replaces the real response with the captured one while handlers are running,
+// so every method of context, such as Redirect, Render, File or Forward, writes into memory
+func (c *context) run() error {
+	resp := c.Context.Response()
+	saved := *resp
+	*resp = *forest.NewResponse(c.writer)
+	defer func() {
+		*resp = saved
+	}()
+	return c.Next()
+}
+
+func (c *context) Next() error {
+	return c.NextWith(c)
+}
+
+func (c *context) NextWith(ctx forest.Context) error {
+	c.index++
+	if c.index < len(c.handlers) {
+		return c.handlers[c.index](ctx)
+	}
+	return nil
+}
+
+func (c *context) Bind(dst interface{}) error {
+	if len(c.req.Params) == 0 {
+		return nil
+	}
+	parent := c.Context.Request()
+	req := parent.Clone(parent.Context())
+	req.Body = io.NopCloser(bytes.NewReader(c.req.Params))
+	req.ContentLength = int64(len(c.req.Params))
+	if err := binder.JSON.Bind(req, dst); err != nil {
+		return NewError(CodeInvalidParams, "Invalid params", err.Error())
+	}
+	return nil
+}
+
+func (c *context) BindAll(dst interface{}) error {
+	return c.Bind(dst)
+}
+
+func newContext(c forest.Context, req *Request, middlewares, handlers []forest.HandlerFunc) *context {
+	hs := make([]forest.HandlerFunc, 0, len(middlewares)+len(handlers))
+	hs = append(hs, middlewares...)
+	hs = append(hs, handlers...)
+	return &context{
+		Context:  c,
+		req:      req,
+		writer:   &responseWriter{header: make(http.Header)},
+		handlers: hs,
+		index:    -1,
+	}
+}
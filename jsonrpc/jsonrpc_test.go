@@ -0,0 +1,163 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/forest"
+	"github.com/honmaple/forest/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type addParams struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+func testRequest(router http.Handler, body string) (int, string) {
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+	req.SetBasicAuth("admin", "admin")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestServer(t *testing.T) {
+	s := New()
+	s.Register("add", func(c forest.Context) error {
+		p := addParams{}
+		if err := c.Bind(&p); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, p.A+p.B)
+	})
+	s.Register("echo", func(c forest.Context) error {
+		return c.String(http.StatusOK, "echo")
+	})
+	s.Register("secret", middleware.BasicAuth(func(user, pass string) bool {
+		return false
+	}), func(c forest.Context) error {
+		return c.String(http.StatusOK, "secret")
+	})
+	s.Register("error", func(c forest.Context) error {
+		return NewError(1, "custom error")
+	})
+
+	router := forest.New()
+	s.Mount(router.Group(), "/rpc")
+
+	code, body := testRequest(router, `{"jsonrpc": "2.0", "method": "add", "params": {"a": 1, "b": 2}, "id": 1}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": 3, "id": 1}`, body)
+
+	_, body = testRequest(router, `{"jsonrpc": "2.0", "method": "add", "params": [1, 2], "id": 1}`)
+	assert.Contains(t, body, `"code":-32602`)
+
+	_, body = testRequest(router, `{"jsonrpc": "2.0", "method": "echo", "id": "a"}`)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": "echo", "id": "a"}`, body)
+
+	_, body = testRequest(router, `{"jsonrpc": "2.0", "method": "unknown", "id": 1}`)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32601, "message": "Method not found"}, "id": 1}`, body)
+
+	_, body = testRequest(router, `{"jsonrpc": "2.0", "method": "secret", "id": 1}`)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32000, "message": "Unauthorized", "data": 401}, "id": 1}`, body)
+
+	_, body = testRequest(router, `{"jsonrpc": "2.0", "method": "error", "id": 1}`)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": 1, "message": "custom error"}, "id": 1}`, body)
+
+	_, body = testRequest(router, `{"jsonrpc": "2.0", "method"`)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`, body)
+
+	code, _ = testRequest(router, `{"jsonrpc": "2.0", "method": "echo"}`)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, body = testRequest(router, `{"jsonrpc": "2.0", "method": "error"}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, "", body)
+
+	code, body = testRequest(router, `{"jsonrpc": "2.0", "method": "unknown"}`)
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, "", body)
+
+	code, _ = testRequest(router, `[{"jsonrpc": "2.0", "method": "error"}, {"jsonrpc": "2.0", "method": "unknown"}]`)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	_, body = testRequest(router, `[
+{"jsonrpc": "2.0", "method": "add", "params": {"a": 1, "b": 2}, "id": 1},
+{"jsonrpc": "2.0", "method": "echo"},
+1
+]`)
+	assert.JSONEq(t, `[
+{"jsonrpc": "2.0", "result": 3, "id": 1},
+{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}
+]`, body)
+
+	_, body = testRequest(router, `[]`)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`, body)
+
+	s.MaxBodySize = 64
+	payload := `[` + strings.Repeat(`{"jsonrpc": "2.0", "method": "echo", "id": 1},`, 10) + `]`
+	code, _ = testRequest(router, payload)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, code)
+
+	// body without Content-Length is limited too
+	req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(payload))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	code, body = testRequest(router, `{"jsonrpc": "2.0", "method": "echo", "id": "a"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "result": "echo", "id": "a"}`, body)
+}
+
+func TestServerResponse(t *testing.T) {
+	s := New()
+	s.Register("redirect", func(c forest.Context) error {
+		return c.Redirect(http.StatusFound, "/login")
+	})
+	s.Register("jsonp", func(c forest.Context) error {
+		return c.JSONP(http.StatusOK, "cb", 1)
+	})
+	s.Register("forward", func(c forest.Context) error {
+		return c.Forward("hello")
+	})
+	s.Register("header", func(c forest.Context) error {
+		c.Response().Header().Set("X-Method", "header")
+		return c.XML(http.StatusOK, "header")
+	})
+
+	router := forest.New()
+	router.POST("/hello", func(c forest.Context) error {
+		return c.String(http.StatusOK, "hello")
+	}).Named("hello")
+	middlewares := make([]forest.HandlerFunc, 1, 2)
+	middlewares[0] = func(c forest.Context) error {
+		return c.Next()
+	}
+	s.Mount(router.Group(), "/rpc", middlewares...)
+	s.Mount(router.Group(), "/rpc2", middlewares...)
+	// Mount must not append the handler into the spare capacity of middlewares
+	assert.Nil(t, middlewares[:2][1])
+
+	for _, c := range []struct {
+		method string
+		result string
+	}{
+		{"redirect", `null`},
+		{"jsonp", `"cb(1\n);"`},
+		{"forward", `"hello"`},
+		{"header", `"\u003cstring\u003eheader\u003c/string\u003e"`},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc": "2.0", "method": "`+c.method+`", "id": 1}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, c.method)
+		assert.Equal(t, "", rec.Header().Get("Location"), c.method)
+		assert.Equal(t, "", rec.Header().Get("X-Method"), c.method)
+		assert.JSONEq(t, `{"jsonrpc": "2.0", "result": `+c.result+`, "id": 1}`, rec.Body.String(), c.method)
+	}
+}
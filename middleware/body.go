@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/honmaple/forest"
+	"github.com/honmaple/forest/binder"
+)
+
+type BodyBufferConfig struct {
+	Skipper Skipper
+	// MemoryLimit is the max size of body in memory, the larger body will be written to a temp file
+	MemoryLimit int64
+	// Limit is the max size of body, 413 will be returned if the body is larger, -1 means no limit
+	Limit   int64
+	TempDir string
+}
+
+var (
+	DefaultBodyBufferConfig = BodyBufferConfig{
+		MemoryLimit: 1 << 20,
+		Limit:       32 << 20,
+	}
+)
+
+func BodyBuffer() forest.HandlerFunc {
+	return BodyBufferWithConfig(DefaultBodyBufferConfig)
+}
+
+func BodyBufferWithConfig(config BodyBufferConfig) forest.HandlerFunc {
+	if config.MemoryLimit == 0 {
+		config.MemoryLimit = DefaultBodyBufferConfig.MemoryLimit
+	}
+	if config.Limit == 0 {
+		config.Limit = DefaultBodyBufferConfig.Limit
+	}
+	return func(c forest.Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		req := c.Request()
+		if req.Body == nil || req.Body == http.NoBody {
+			return c.Next()
+		}
+		if _, ok := req.Body.(*forest.Body); ok {
+			return c.Next()
+		}
+		if config.Limit > 0 && req.ContentLength > config.Limit {
+			return forest.NewError(http.StatusRequestEntityTooLarge)
+		}
+		body, err := forest.NewBody(req.Body, config.MemoryLimit, config.Limit, config.TempDir)
+		if err != nil {
+			if errors.Is(err, binder.ErrBodyTooLarge) {
+				return forest.NewError(http.StatusRequestEntityTooLarge)
+			}
+			return err
+		}
+		req.Body.Close()
+		req.Body = body
+		c.OnFinish(func(forest.Context) {
+			body.Remove()
+		})
+		return c.Next()
+	}
+}
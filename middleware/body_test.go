@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/honmaple/forest"
+	"github.com/honmaple/forest/binder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyBuffer(t *testing.T) {
+	dir := t.TempDir()
+
+	router := forest.New()
+	router.Use(BodyBufferWithConfig(BodyBufferConfig{MemoryLimit: 4, Limit: 32, TempDir: dir}))
+	router.POST("/", func(c forest.Context) error {
+		files, _ := os.ReadDir(dir)
+
+		var first, second string
+		if err := c.BindWith(&first, binder.Text); err != nil {
+			return err
+		}
+		if err := c.BindWith(&second, binder.Text); err != nil {
+			return err
+		}
+		body, err := c.Body()
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%d %s %s %s", len(files), first, second, body)
+	})
+
+	testBody := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/plain")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := testBody("abc")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "0 abc abc abc", rec.Body.String())
+
+	rec = testBody("hello forest")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1 hello forest hello forest hello forest", rec.Body.String())
+
+	// temp file is removed after request
+	files, _ := os.ReadDir(dir)
+	assert.Empty(t, files)
+
+	rec = testBody(strings.Repeat("a", 33))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 33)))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/honmaple/forest"
+)
+
+type BufferConfig struct {
+	Skipper Skipper
+	Limit   int
+}
+
+var (
+	DefaultBufferConfig = BufferConfig{
+		Limit: 1 << 20,
+	}
+)
+
+func Buffer() forest.HandlerFunc {
+	return BufferWithConfig(DefaultBufferConfig)
+}
+
+func BufferWithConfig(config BufferConfig) forest.HandlerFunc {
+	if config.Limit == 0 {
+		config.Limit = DefaultBufferConfig.Limit
+	}
+	return func(c forest.Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		c.Response().Buffer(config.Limit)
+		return c.Next()
+	}
+}
@@ -28,6 +28,12 @@ type (
 		Reset()
 		Format(*http.Request, http.ResponseWriter, int) string
 	}
+	// ContextLoggerFormatter is an optional interface of LoggerFormatter,
+	// FormatContext will be used instead of Format if it's implemented
+	ContextLoggerFormatter interface {
+		LoggerFormatter
+		FormatContext(forest.Context, int) string
+	}
 	loggerFormatter struct {
 		start time.Time
 	}
@@ -49,6 +55,14 @@ func (f *loggerFormatter) Reset() {
 }
 
 func (f *loggerFormatter) Format(req *http.Request, resp http.ResponseWriter, status int) string {
+	return f.format(req, req.RemoteAddr, status)
+}
+
+func (f *loggerFormatter) FormatContext(c forest.Context, status int) string {
+	return f.format(c.Request(), c.RealIP(), status)
+}
+
+func (f *loggerFormatter) format(req *http.Request, ip string, status int) string {
 	statusColor := greenColor
 	if status >= 300 && status < 400 {
 		statusColor = cyanColor
@@ -60,7 +74,7 @@ func (f *loggerFormatter) Format(req *http.Request, resp http.ResponseWriter, st
 
 	end := time.Now()
 	return fmt.Sprintf("%s - [%s] \"%s %s%s %s\" %s%03d%s - %s\n",
-		req.RemoteAddr,
+		ip,
 		f.start.Format("02/Jan/2006 15:04:05.00000"),
 		req.Method, req.Host, req.RequestURI, req.Proto,
 		statusColor, status, resetColor,
@@ -86,10 +100,13 @@ func LoggerWithConfig(config LoggerConfig) forest.HandlerFunc {
 		}
 		f.Reset()
 
-		req := c.Request()
 		err := c.Next()
 		resp := c.Response()
-		fmt.Fprint(config.Output, f.Format(req, resp, resp.Status))
+		if cf, ok := f.(ContextLoggerFormatter); ok {
+			fmt.Fprint(config.Output, cf.FormatContext(c, resp.Status))
+		} else {
+			fmt.Fprint(config.Output, f.Format(c.Request(), resp, resp.Status))
+		}
 		return err
 	}
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/honmaple/forest"
+	"github.com/stretchr/testify/assert"
+)
+
+type testLoggerFormatter struct{}
+
+func (testLoggerFormatter) Reset() {}
+
+func (testLoggerFormatter) Format(req *http.Request, resp http.ResponseWriter, status int) string {
+	return req.RemoteAddr
+}
+
+func TestLogger(t *testing.T) {
+	out := new(bytes.Buffer)
+	router := forest.New(forest.TrustedProxies("192.0.2.0/24"))
+	router.Use(LoggerWithConfig(LoggerConfig{Output: out}))
+	router.GET("/", func(c forest.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Contains(t, out.String(), "203.0.113.1 - ")
+
+	out.Reset()
+	router = forest.New(forest.TrustedProxies("192.0.2.0/24"))
+	router.Use(LoggerWithConfig(LoggerConfig{
+		Output:    out,
+		Formatter: func() LoggerFormatter { return testLoggerFormatter{} },
+	}))
+	router.GET("/", func(c forest.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "192.0.2.1:1234", out.String())
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/honmaple/forest"
+	"github.com/honmaple/forest/binder"
+)
+
+type UploadConfig struct {
+	Skipper   Skipper
+	MaxMemory int64
+	// MaxFiles limits the count of uploaded files
+	MaxFiles int
+	// MaxFileSize limits the size of each uploaded file
+	MaxFileSize int64
+	// AllowedTypes is checked by content sniffing, "image/*" is supported
+	AllowedTypes []string
+	// MaxBodySize limits the size of request body before parsing,
+	// default is MaxFiles*MaxFileSize+1MB if both of them are set
+	MaxBodySize int64
+}
+
+type countReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func detectContentType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	ctype := http.DetectContentType(buf[:n])
+	if index := strings.IndexByte(ctype, ';'); index > -1 {
+		ctype = ctype[:index]
+	}
+	return ctype, nil
+}
+
+func matchContentType(ctype string, allowedTypes []string) bool {
+	for _, t := range allowedTypes {
+		if t == "*" || t == "*/*" || t == ctype {
+			return true
+		}
+		if strings.HasSuffix(t, "/*") && strings.HasPrefix(ctype, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func Upload(maxFiles int, maxFileSize int64, allowedTypes ...string) forest.HandlerFunc {
+	return UploadWithConfig(UploadConfig{
+		MaxFiles:     maxFiles,
+		MaxFileSize:  maxFileSize,
+		AllowedTypes: allowedTypes,
+	})
+}
+
+func UploadWithConfig(config UploadConfig) forest.HandlerFunc {
+	if config.MaxBodySize == 0 && config.MaxFiles > 0 && config.MaxFileSize > 0 {
+		config.MaxBodySize = int64(config.MaxFiles)*config.MaxFileSize + 1<<20
+	}
+	return func(c forest.Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		req := c.Request()
+
+		var body *countReader
+		if config.MaxBodySize > 0 && req.Body != nil && req.MultipartForm == nil {
+			if req.ContentLength > config.MaxBodySize {
+				return forest.NewError(http.StatusRequestEntityTooLarge)
+			}
+			body = &countReader{ReadCloser: req.Body}
+			req.Body = http.MaxBytesReader(c.Response(), body, config.MaxBodySize)
+		}
+		if err := binder.ParseForm(req, config.MaxMemory); err != nil {
+			if err == http.ErrNotMultipart {
+				return c.Next()
+			}
+			if body != nil && body.n > config.MaxBodySize {
+				return forest.NewError(http.StatusRequestEntityTooLarge)
+			}
+			return forest.NewError(http.StatusBadRequest, err.Error())
+		}
+		count := 0
+		for _, files := range req.MultipartForm.File {
+			for _, file := range files {
+				count++
+				if config.MaxFiles > 0 && count > config.MaxFiles {
+					return forest.NewError(http.StatusRequestEntityTooLarge, "too many files")
+				}
+				if config.MaxFileSize > 0 && file.Size > config.MaxFileSize {
+					return forest.NewError(http.StatusRequestEntityTooLarge, "file too large: "+file.Filename)
+				}
+				if len(config.AllowedTypes) == 0 {
+					continue
+				}
+				ctype, err := detectContentType(file)
+				if err != nil {
+					return err
+				}
+				if !matchContentType(ctype, config.AllowedTypes) {
+					return forest.NewError(http.StatusUnsupportedMediaType, "file type is not allowed: "+file.Filename)
+				}
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/honmaple/forest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadMatchContentType(t *testing.T) {
+	assert.True(t, matchContentType("image/png", []string{"image/*"}))
+	assert.True(t, matchContentType("image/png", []string{"text/plain", "image/png"}))
+	assert.True(t, matchContentType("text/plain", []string{"*/*"}))
+	assert.False(t, matchContentType("text/plain", []string{"image/*"}))
+	assert.False(t, matchContentType("imagex/png", []string{"image/*"}))
+}
+
+func testUploadRequest(t *testing.T, router *forest.Forest, files map[string][]byte) *httptest.ResponseRecorder {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	for name, content := range files {
+		fw, err := w.CreateFormFile("file", name)
+		assert.NoError(t, err)
+		fw.Write(content)
+	}
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUpload(t *testing.T) {
+	png := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, 100)...)
+
+	router := forest.New()
+	router.POST("/", Upload(2, 200, "image/*"), func(c forest.Context) error {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%d", len(form.File["file"]))
+	})
+
+	rec := testUploadRequest(t, router, map[string][]byte{"a.png": png, "b.png": png})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Body.String())
+
+	rec = testUploadRequest(t, router, map[string][]byte{"a.png": png, "b.png": png, "c.png": png})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = testUploadRequest(t, router, map[string][]byte{"a.png": append(png, make([]byte, 200)...)})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// sniffed as text/plain although the name is .png
+	rec = testUploadRequest(t, router, map[string][]byte{"a.png": []byte("hello")})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	// body is rejected before parsing
+	router = forest.New()
+	router.POST("/", UploadWithConfig(UploadConfig{MaxBodySize: 100}), func(c forest.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	rec = testUploadRequest(t, router, map[string][]byte{"a.png": png, "b.png": png})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	fw, _ := w.CreateFormFile("file", "a.png")
+	fw.Write(append(png, make([]byte, 200)...))
+	w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(body))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
@@ -0,0 +1,191 @@
+package forest
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ProxyHeaderXForwarded trusts X-Forwarded-For, X-Forwarded-Proto,
+	// X-Forwarded-Host and X-Real-IP headers from trusted proxies
+	ProxyHeaderXForwarded = "X-Forwarded"
+	// ProxyHeaderForwarded trusts Forwarded header (RFC 7239) from trusted proxies
+	ProxyHeaderForwarded = "Forwarded"
+)
+
+const (
+	headerForwarded       = "Forwarded"
+	headerXForwardedFor   = "X-Forwarded-For"
+	headerXForwardedHost  = "X-Forwarded-Host"
+	headerXForwardedProto = "X-Forwarded-Proto"
+	headerXRealIP         = "X-Real-IP"
+)
+
+// TrustedProxies sets the ip or CIDR list of trusted proxies,
+// proxy headers are ignored unless the request comes from a trusted proxy
+func TrustedProxies(proxies ...string) Option {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				panic("forest: invalid proxy ip " + proxy)
+			}
+			if ip.To4() != nil {
+				proxy = proxy + "/32"
+			} else {
+				proxy = proxy + "/128"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic("forest: invalid proxy " + proxy)
+		}
+		nets = append(nets, ipnet)
+	}
+	return func(e *Forest) {
+		e.trustedProxies = nets
+	}
+}
+
+// TrustedHops limits the max number of trusted proxies in front of server,
+// 0 means no limit
+func TrustedHops(hops int) Option {
+	return func(e *Forest) {
+		e.trustedHops = hops
+	}
+}
+
+// TrustedHeader sets which proxy headers are trusted, either
+// ProxyHeaderXForwarded (default) or ProxyHeaderForwarded,
+// the other one is always ignored so that clients can't spoof it
+func TrustedHeader(header string) Option {
+	if header != ProxyHeaderXForwarded && header != ProxyHeaderForwarded {
+		panic("forest: invalid proxy header " + header)
+	}
+	return func(e *Forest) {
+		e.trustedHeader = header
+	}
+}
+
+func (e *Forest) isTrustedProxy(ip string) bool {
+	if e == nil || len(e.trustedProxies) == 0 {
+		return false
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range e.trustedProxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Forest) realIP(r *http.Request) string {
+	ip := parseAddr(r.RemoteAddr)
+	if !e.isTrustedProxy(ip) {
+		return ip
+	}
+
+	var ips []string
+	if e.trustedHeader == ProxyHeaderForwarded {
+		ips = forwardedValues(r.Header, "for")
+	} else {
+		for _, v := range r.Header.Values(headerXForwardedFor) {
+			for _, s := range strings.Split(v, ",") {
+				ips = append(ips, parseAddr(strings.TrimSpace(s)))
+			}
+		}
+		if len(ips) == 0 {
+			if v := r.Header.Get(headerXRealIP); v != "" {
+				return parseAddr(v)
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return ip
+	}
+
+	hops := 1
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip = ips[i]
+		if !e.isTrustedProxy(ip) || (e.trustedHops > 0 && hops >= e.trustedHops) {
+			return ip
+		}
+		hops++
+	}
+	return ip
+}
+
+func (e *Forest) scheme(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if !e.isTrustedProxy(parseAddr(r.RemoteAddr)) {
+		return scheme
+	}
+	if e.trustedHeader == ProxyHeaderForwarded {
+		if values := forwardedValues(r.Header, "proto"); len(values) > 0 {
+			return strings.ToLower(values[len(values)-1])
+		}
+	} else if v := lastValue(r.Header.Get(headerXForwardedProto)); v != "" {
+		return strings.ToLower(v)
+	}
+	return scheme
+}
+
+func (e *Forest) host(r *http.Request) string {
+	if !e.isTrustedProxy(parseAddr(r.RemoteAddr)) {
+		return r.Host
+	}
+	if e.trustedHeader == ProxyHeaderForwarded {
+		if values := forwardedValues(r.Header, "host"); len(values) > 0 {
+			return values[len(values)-1]
+		}
+	} else if v := lastValue(r.Header.Get(headerXForwardedHost)); v != "" {
+		return v
+	}
+	return r.Host
+}
+
+func lastValue(v string) string {
+	if index := strings.LastIndexByte(v, ','); index > -1 {
+		v = v[index+1:]
+	}
+	return strings.TrimSpace(v)
+}
+
+// parseAddr removes port and brackets from address
+func parseAddr(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+}
+
+// forwardedValues returns values of key from Forwarded header (RFC 7239)
+// Forwarded: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+func forwardedValues(header http.Header, key string) []string {
+	var values []string
+	for _, v := range header.Values(headerForwarded) {
+		for _, elem := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				index := strings.IndexByte(pair, '=')
+				if index < 0 || !strings.EqualFold(strings.TrimSpace(pair[:index]), key) {
+					continue
+				}
+				value := strings.Trim(strings.TrimSpace(pair[index+1:]), `"`)
+				if key == "for" {
+					value = parseAddr(value)
+				}
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,82 @@
+package forest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyRealIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2, 10.0.0.2")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "proxy.com")
+
+	e := New()
+	assert.Equal(t, "10.0.0.1", e.realIP(req))
+	assert.Equal(t, "http", e.scheme(req))
+	assert.Equal(t, "example.com", e.host(req))
+
+	e = New(TrustedProxies("10.0.0.0/8"))
+	assert.Equal(t, "2.2.2.2", e.realIP(req))
+	assert.Equal(t, "https", e.scheme(req))
+	assert.Equal(t, "proxy.com", e.host(req))
+
+	e = New(TrustedProxies("10.0.0.0/8", "2.2.2.2"))
+	assert.Equal(t, "1.1.1.1", e.realIP(req))
+
+	e = New(TrustedProxies("10.0.0.0/8", "2.2.2.2"), TrustedHops(2))
+	assert.Equal(t, "2.2.2.2", e.realIP(req))
+
+	req.Header.Del("X-Forwarded-For")
+	req.Header.Set("X-Real-IP", "3.3.3.3")
+	assert.Equal(t, "3.3.3.3", e.realIP(req))
+
+	req.Header.Set("Forwarded", `for=192.0.2.60;proto=http;host=a.com, for="[2001:db8:cafe::17]:4711"`)
+	assert.Equal(t, "3.3.3.3", e.realIP(req))
+	assert.Equal(t, "https", e.scheme(req))
+	assert.Equal(t, "proxy.com", e.host(req))
+
+	e = New(TrustedProxies("10.0.0.0/8", "2.2.2.2"), TrustedHeader(ProxyHeaderForwarded))
+	assert.Equal(t, "2001:db8:cafe::17", e.realIP(req))
+	assert.Equal(t, "http", e.scheme(req))
+	assert.Equal(t, "a.com", e.host(req))
+}
+
+func TestProxyContext(t *testing.T) {
+	router := New(TrustedProxies("127.0.0.1"))
+	router.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "%s %s %s", c.RealIP(), c.Scheme(), c.Host())
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, "1.1.1.1 http example.com", rec.Body.String())
+}
+
+func TestProxySpoofForwarded(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	req.Header.Set("Forwarded", "for=1.2.3.4;proto=https;host=evil.com")
+
+	e := New(TrustedProxies("10.0.0.0/8"))
+	assert.Equal(t, "1.1.1.1", e.realIP(req))
+	assert.Equal(t, "http", e.scheme(req))
+	assert.Equal(t, "example.com", e.host(req))
+
+	req.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "10.0.0.1", e.realIP(req))
+
+	e = New(TrustedProxies("10.0.0.0/8"), TrustedHeader(ProxyHeaderForwarded))
+	req.Header.Set("X-Forwarded-For", "1.1.1.1")
+	req.Header.Set("Forwarded", "for=5.5.5.5")
+	assert.Equal(t, "5.5.5.5", e.realIP(req))
+
+	assert.Panics(t, func() { TrustedHeader("X-Real-IP") })
+}
@@ -0,0 +1,99 @@
+package forest
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// pathParam returns the unescaped param, params are matched with raw path
+// when the request path contains escaped "/" or other reserved characters
+func pathParam(c Context, name string) string {
+	value := c.Param(name)
+	if c.Request().URL.RawPath == "" {
+		return value
+	}
+	if v, err := url.PathUnescape(value); err == nil {
+		return v
+	}
+	return value
+}
+
+// escapePath escapes param value used in redirect path, the value of wildcard
+// param keeps "/" between segments
+func escapePath(value string, wildcard bool) string {
+	if !wildcard {
+		return url.PathEscape(value)
+	}
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// expandPath replaces {name} in path with escaped params of current request,
+// /posts/{slug} => /posts/hello, use {name:path} or {*} to keep "/" of wildcard param
+func expandPath(path string, c Context) string {
+	if !strings.Contains(path, "{") {
+		return path
+	}
+	b := new(strings.Builder)
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		name, rule := path[start+1:end], ""
+		if index := strings.IndexByte(name, ':'); index > -1 {
+			name, rule = name[:index], name[index+1:]
+		}
+		name = strings.TrimSuffix(name, "?")
+		b.WriteString(path[:start])
+		b.WriteString(escapePath(pathParam(c, name), name == "*" || rule == "path"))
+		path = path[end+1:]
+	}
+	b.WriteString(path)
+	return b.String()
+}
+
+// Redirect registers routes which redirect from path to another path or named route,
+// the params of from path can be used in the target path, such as
+//
+//	g.Redirect("/blog/{slug}", "/posts/{slug}", http.StatusMovedPermanently)
+//	g.Redirect("/blog/{slug}", "posts.show", http.StatusMovedPermanently)
+func (g *Group) Redirect(from, to string, code int) Routes {
+	if code == 0 {
+		code = http.StatusMovedPermanently
+	}
+	handler := func(c Context) error {
+		url := to
+		if strings.HasPrefix(to, "/") || strings.Contains(to, "://") {
+			url = expandPath(to, c)
+		} else {
+			route := c.Forest().Route(to)
+			if route == nil {
+				return NewError(http.StatusInternalServerError, "redirect route not found: "+to)
+			}
+			args := make([]interface{}, len(route.pnames))
+			for i, pname := range route.pnames {
+				args[i] = escapePath(pathParam(c, pname.name), pname.wildcard(route.path))
+			}
+			url = route.URL(args...)
+		}
+		// a leading "//" is treated as a protocol-relative url by browsers
+		if !strings.HasPrefix(to, "//") && (strings.HasPrefix(url, "//") || strings.HasPrefix(url, "/\\")) {
+			return NewError(http.StatusBadRequest, "invalid redirect path")
+		}
+		if query := c.Request().URL.RawQuery; query != "" && !strings.Contains(url, "?") {
+			url = url + "?" + query
+		}
+		return c.Redirect(code, url)
+	}
+	return g.Any(from, handler)
+}
@@ -0,0 +1,216 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	ContentTypeCSV = "text/csv"
+	ContentTypeGob = "application/x-gob"
+)
+
+// RenderFunc renders data with the registered media type
+type RenderFunc func(http.ResponseWriter, int, interface{}) error
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]RenderFunc{
+		ContentTypeJSON: JSON,
+		ContentTypeXML:  XML,
+		"text/xml":      XML,
+		ContentTypeText: func(w http.ResponseWriter, code int, data interface{}) error {
+			switch v := data.(type) {
+			case string:
+				return Text(w, code, v)
+			case []byte:
+				return Text(w, code, string(v))
+			case fmt.Stringer:
+				return Text(w, code, v.String())
+			}
+			return fmt.Errorf("%w: %T as %s", ErrNotAcceptable, data, ContentTypeText)
+		},
+		ContentTypeCSV: CSV,
+	}
+	// ErrNotAcceptable is returned before writing response when data can't be rendered as the media type
+	ErrNotAcceptable = errors.New("render: not acceptable")
+)
+
+// MediaType returns the lower case media type without parameters
+func MediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		if index := strings.IndexByte(contentType, ';'); index > -1 {
+			contentType = contentType[:index]
+		}
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// MediaTypeSuffix returns the media type of structured syntax suffix,
+// such as application/json for application/problem+json
+func MediaTypeSuffix(mediaType string) (string, bool) {
+	index := strings.LastIndexByte(mediaType, '+')
+	if index < 0 || index == len(mediaType)-1 {
+		return "", false
+	}
+	return "application/" + mediaType[index+1:], true
+}
+
+// Register registers render func with media type, nil func means unregister
+func Register(mediaType string, fn RenderFunc) {
+	mediaType = MediaType(mediaType)
+	if mediaType == "" {
+		panic("render: register with empty media type")
+	}
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	if fn == nil {
+		delete(renderers, mediaType)
+		return
+	}
+	renderers[mediaType] = fn
+}
+
+// Lookup returns the render func of content type, the structured syntax suffix
+// is used if the media type is not registered
+func Lookup(contentType string) (RenderFunc, bool) {
+	fn, _, ok := lookup(MediaType(contentType))
+	return fn, ok
+}
+
+func lookup(mediaType string) (RenderFunc, bool, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	if fn, ok := renderers[mediaType]; ok {
+		return fn, true, true
+	}
+	if suffix, ok := MediaTypeSuffix(mediaType); ok {
+		fn, ok := renderers[suffix]
+		return fn, false, ok
+	}
+	return nil, false, false
+}
+
+// Render renders data with the render func of content type, such as application/problem+json
+func Render(w http.ResponseWriter, code int, contentType string, data interface{}) error {
+	fn, exact, ok := lookup(MediaType(contentType))
+	if !ok {
+		return errors.New("render: unknown content type: " + contentType)
+	}
+	// keep the original content type with parameters or structured syntax suffix
+	override := (!exact || strings.Contains(contentType, ";")) && w.Header().Get(ContentType) == ""
+	if override {
+		writeContentType(w, contentType)
+	}
+	err := fn(w, code, data)
+	if override && errors.Is(err, ErrNotAcceptable) {
+		w.Header().Del(ContentType)
+	}
+	return err
+}
+
+func toText(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// CSV renders [][]string, or slice of struct with csv tag as header
+func CSV(w http.ResponseWriter, code int, data interface{}) error {
+	records, err := csvRecords(data)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	cw := csv.NewWriter(buf)
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return Blob(w, code, ContentTypeCSV+"; "+charsetUTF8, buf.Bytes())
+}
+
+func csvRecords(data interface{}) ([][]string, error) {
+	if records, ok := data.([][]string); ok {
+		return records, nil
+	}
+	val := reflect.ValueOf(data)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice {
+		return nil, errors.New("render: csv data must be [][]string or slice of struct")
+	}
+	elemType := val.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, errors.New("render: csv data must be [][]string or slice of struct")
+	}
+
+	var (
+		header = make([]string, 0, elemType.NumField())
+		fields = make([]int, 0, elemType.NumField())
+	)
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("csv"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		header = append(header, name)
+		fields = append(fields, i)
+	}
+
+	records := make([][]string, 0, val.Len()+1)
+	records = append(records, header)
+	for i := 0; i < val.Len(); i++ {
+		elem := val.Index(i)
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		record := make([]string, len(fields))
+		if elem.IsValid() {
+			for j, index := range fields {
+				record[j] = toText(elem.Field(index).Interface())
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Gob renders data with encoding/gob, which is not registered by default,
+// use Register(ContentTypeGob, Gob) to enable it
+func Gob(w http.ResponseWriter, code int, data interface{}) error {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(data); err != nil {
+		return err
+	}
+	return Blob(w, code, ContentTypeGob, buf.Bytes())
+}
@@ -0,0 +1,78 @@
+package render
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMediaType(t *testing.T) {
+	assert.Equal(t, "application/json", MediaType("Application/JSON; charset=UTF-8"))
+	assert.Equal(t, "text/plain", MediaType(" text/plain ;"))
+	assert.Equal(t, "", MediaType(""))
+
+	suffix, ok := MediaTypeSuffix("application/problem+json")
+	assert.True(t, ok)
+	assert.Equal(t, "application/json", suffix)
+	_, ok = MediaTypeSuffix("application/json")
+	assert.False(t, ok)
+}
+
+func TestRender(t *testing.T) {
+	type record struct {
+		ID      int    `csv:"id"`
+		Name    string `csv:"name"`
+		Ignored string `csv:"-"`
+	}
+
+	rec := httptest.NewRecorder()
+	assert.Nil(t, Render(rec, http.StatusOK, "text/csv", []record{{1, "a", ""}, {2, "b", ""}}))
+	assert.Equal(t, "text/csv; charset=UTF-8", rec.Header().Get(ContentType))
+	assert.Equal(t, "id,name\n1,a\n2,b\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	assert.Nil(t, Render(rec, http.StatusOK, "application/problem+json", map[string]string{"title": "error"}))
+	assert.Equal(t, "application/problem+json", rec.Header().Get(ContentType))
+	assert.Equal(t, "{\"title\":\"error\"}\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	assert.Nil(t, Render(rec, http.StatusCreated, "text/plain", []byte("123")))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, ContentTypeTextCharsetUTF8, rec.Header().Get(ContentType))
+	assert.Equal(t, "123", rec.Body.String())
+
+	// only string, []byte and fmt.Stringer can be rendered as text
+	rec = httptest.NewRecorder()
+	assert.True(t, errors.Is(Render(rec, http.StatusOK, "text/plain", 123), ErrNotAcceptable))
+	assert.False(t, rec.Flushed)
+	assert.Equal(t, 0, rec.Body.Len())
+	assert.Equal(t, "", rec.Header().Get(ContentType))
+	assert.True(t, errors.Is(Render(rec, http.StatusOK, "text/plain; charset=UTF-8", struct{}{}), ErrNotAcceptable))
+	assert.Equal(t, "", rec.Header().Get(ContentType))
+
+	// gob is opt-in
+	_, ok := Lookup(ContentTypeGob)
+	assert.False(t, ok)
+	Register(ContentTypeGob, Gob)
+	defer Register(ContentTypeGob, nil)
+	_, ok = Lookup(ContentTypeGob)
+	assert.True(t, ok)
+
+	rec = httptest.NewRecorder()
+	assert.NotNil(t, Render(rec, http.StatusOK, "application/unknown", nil))
+	assert.NotNil(t, Render(rec, http.StatusOK, "text/csv", []int{1}))
+
+	Register("application/x-custom", func(w http.ResponseWriter, code int, data interface{}) error {
+		return Blob(w, code, "application/x-custom", []byte("custom"))
+	})
+	defer Register("application/x-custom", nil)
+	_, ok = Lookup("Application/X-Custom; v=1")
+	assert.True(t, ok)
+
+	rec = httptest.NewRecorder()
+	assert.Nil(t, Render(rec, http.StatusOK, "application/x-custom", nil))
+	assert.Equal(t, "custom", rec.Body.String())
+}
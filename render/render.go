@@ -2,7 +2,12 @@ package render
 
 import (
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"unicode/utf8"
 )
 
 const (
@@ -19,8 +24,53 @@ const (
 	ContentTypeJSONP            = "application/javascript"
 	ContentTypeJSONPCharsetUTF8 = ContentTypeJSONP + "; " + charsetUTF8
 	ContentTypeMultipartForm    = "multipart/form-data"
+	ContentTypeOctetStream      = "application/octet-stream"
+	ContentDispositionHeader    = "Content-Disposition"
 )
 
+func isAttrChar(c byte) bool {
+	if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// ContentDisposition returns the value of Content-Disposition header,
+// filename* (RFC 6266) is added when filename contains non-ASCII characters
+func ContentDisposition(dtype, filename string) string {
+	if filename == "" {
+		return dtype
+	}
+	ascii := true
+	fallback := new(strings.Builder)
+	for _, r := range filename {
+		switch {
+		case r >= utf8.RuneSelf:
+			ascii = false
+			fallback.WriteByte('_')
+		case r < ' ' || r == 0x7f:
+			fallback.WriteByte('_')
+		case r == '"' || r == '\\':
+			fallback.WriteByte('\\')
+			fallback.WriteRune(r)
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	if ascii {
+		return fmt.Sprintf(`%s; filename="%s"`, dtype, fallback.String())
+	}
+	encoded := new(strings.Builder)
+	for i := 0; i < len(filename); i++ {
+		if c := filename[i]; isAttrChar(c) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(encoded, "%%%02X", c)
+		}
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, dtype, fallback.String(), encoded.String())
+}
+
 func writeContentType(w http.ResponseWriter, v string) {
 	header := w.Header()
 	if header.Get(ContentType) == "" {
@@ -40,6 +90,15 @@ func Blob(w http.ResponseWriter, code int, contentType string, data []byte) (err
 	return
 }
 
+func Stream(w http.ResponseWriter, code int, contentType string, r io.Reader) (err error) {
+	writeContentType(w, contentType)
+	if code > 0 {
+		w.WriteHeader(code)
+	}
+	_, err = io.Copy(w, r)
+	return
+}
+
 func Bytes(w http.ResponseWriter, code int, data []byte) error {
 	return Blob(w, code, ContentTypeTextCharsetUTF8, data)
 }
@@ -77,9 +136,9 @@ func JSONP(w http.ResponseWriter, code int, callback string, data interface{}) (
 }
 
 func XML(w http.ResponseWriter, code int, data interface{}) error {
-	writeContentType(w, ContentTypeJSONCharsetUTF8)
+	writeContentType(w, ContentTypeXMLCharsetUTF8)
 	if code > 0 {
 		w.WriteHeader(code)
 	}
-	return json.NewEncoder(w).Encode(data)
+	return xml.NewEncoder(w).Encode(data)
 }
@@ -0,0 +1,22 @@
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXML(t *testing.T) {
+	type record struct {
+		ID   int    `xml:"id"`
+		Name string `xml:"name"`
+	}
+
+	rec := httptest.NewRecorder()
+	assert.Nil(t, XML(rec, http.StatusCreated, record{1, "forest"}))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, ContentTypeXMLCharsetUTF8, rec.Header().Get(ContentType))
+	assert.Equal(t, "<record><id>1</id><name>forest</name></record>", rec.Body.String())
+}
@@ -0,0 +1,85 @@
+package forest
+
+import (
+	"net/http"
+	"strings"
+)
+
+type (
+	ResourceLister interface {
+		List(Context) error
+	}
+	ResourceCreator interface {
+		Create(Context) error
+	}
+	ResourceShower interface {
+		Show(Context) error
+	}
+	ResourceUpdater interface {
+		Update(Context) error
+	}
+	ResourcePatcher interface {
+		Patch(Context) error
+	}
+	ResourceDeleter interface {
+		Delete(Context) error
+	}
+)
+
+// resourceName returns static segments of path joined with ".",
+// /posts/{post_id}/comments => posts.comments
+func resourceName(path string) string {
+	names := make([]string, 0)
+	for _, s := range strings.Split(path, "/") {
+		if s == "" || strings.ContainsAny(s, "{:*") {
+			continue
+		}
+		names = append(names, s)
+	}
+	return strings.Join(names, ".")
+}
+
+// Resource registers RESTful routes with controller methods:
+//
+//	GET    /posts      List    posts.list
+//	POST   /posts      Create  posts.create
+//	GET    /posts/{id} Show    posts.show
+//	PUT    /posts/{id} Update  posts.update
+//	PATCH  /posts/{id} Patch   posts.patch
+//	DELETE /posts/{id} Delete  posts.delete
+//
+// nested resource is supported with path like /posts/{post_id}/comments
+func (g *Group) Resource(path string, controller interface{}, middlewares ...HandlerFunc) Routes {
+	var (
+		name   = resourceName(path)
+		path1  = strings.TrimSuffix(path, "/")
+		path2  = path1 + "/{id}"
+		routes = make(Routes, 0)
+	)
+	add := func(method, path, action string, handler HandlerFunc) {
+		route := g.Add(method, path, append(middlewares[:len(middlewares):len(middlewares)], handler)...)
+		routes = append(routes, route.Named(name+"."+action))
+	}
+	if c, ok := controller.(ResourceLister); ok {
+		add(http.MethodGet, path1, "list", c.List)
+	}
+	if c, ok := controller.(ResourceCreator); ok {
+		add(http.MethodPost, path1, "create", c.Create)
+	}
+	if c, ok := controller.(ResourceShower); ok {
+		add(http.MethodGet, path2, "show", c.Show)
+	}
+	if c, ok := controller.(ResourceUpdater); ok {
+		add(http.MethodPut, path2, "update", c.Update)
+	}
+	if c, ok := controller.(ResourcePatcher); ok {
+		add(http.MethodPatch, path2, "patch", c.Patch)
+	}
+	if c, ok := controller.(ResourceDeleter); ok {
+		add(http.MethodDelete, path2, "delete", c.Delete)
+	}
+	if len(routes) == 0 {
+		panic("forest: resource controller has no method: " + path)
+	}
+	return routes
+}
@@ -1,6 +1,7 @@
 package forest
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 )
@@ -14,6 +15,11 @@ type (
 		http.ResponseWriter
 		Size   int
 		Status int
+
+		buffer      *bytes.Buffer
+		buffered    bool
+		bufferLimit int
+		befores     []func()
 	}
 )
 
@@ -45,6 +51,10 @@ func (r *Response) WriteHeader(code int) {
 	}
 	r.Size = 0
 	r.Status = code
+	if r.buffered {
+		return
+	}
+	r.before()
 	r.ResponseWriter.WriteHeader(r.Status)
 }
 
@@ -55,17 +65,112 @@ func (r *Response) Write(b []byte) (n int, err error) {
 		}
 		r.WriteHeader(r.Status)
 	}
+	if r.buffered {
+		if r.bufferLimit <= 0 || r.buffer.Len()+len(b) <= r.bufferLimit {
+			n, err = r.buffer.Write(b)
+			r.Size += n
+			return
+		}
+		// body is too large, fallback to streaming
+		if err = r.Commit(); err != nil {
+			return
+		}
+	}
 	n, err = r.ResponseWriter.Write(b)
 	r.Size += n
 	return
 }
 
+// Buffer keeps status, headers and body in memory until Commit is called,
+// so that middlewares can change them after c.Next(). If the body grows
+// larger than limit, the buffered data is flushed and the remaining is
+// written directly, limit <= 0 means no limit.
+func (r *Response) Buffer(limit int) {
+	if r.buffered || r.Written() {
+		return
+	}
+	if r.buffer == nil {
+		r.buffer = new(bytes.Buffer)
+	}
+	r.buffer.Reset()
+	r.buffered = true
+	r.bufferLimit = limit
+}
+
+func (r *Response) Buffered() bool {
+	return r.buffered
+}
+
+func (r *Response) Body() []byte {
+	if !r.buffered {
+		return nil
+	}
+	return r.buffer.Bytes()
+}
+
+func (r *Response) SetBody(b []byte) {
+	if !r.buffered {
+		return
+	}
+	if !r.Written() {
+		r.WriteHeader(r.Status)
+	}
+	r.buffer.Reset()
+	r.buffer.Write(b)
+	r.Size = len(b)
+	r.Header().Del("Content-Length")
+}
+
+// Commit writes the buffered status and body to the underlying writer
+func (r *Response) Commit() (err error) {
+	if !r.buffered {
+		return nil
+	}
+	r.buffered = false
+	if !r.Written() {
+		return nil
+	}
+	r.before()
+	r.ResponseWriter.WriteHeader(r.Status)
+	if r.buffer.Len() > 0 {
+		_, err = r.ResponseWriter.Write(r.buffer.Bytes())
+	}
+	r.buffer.Reset()
+	return
+}
+
+// Before registers a function which is called just before the headers are written
+func (r *Response) Before(fn func()) {
+	r.befores = append(r.befores, fn)
+}
+
+func (r *Response) before() {
+	befores := r.befores
+	r.befores = nil
+	for _, fn := range befores {
+		fn()
+	}
+}
+
+func (r *Response) finish() {
+	if !r.Written() && len(r.befores) > 0 {
+		r.WriteHeader(r.Status)
+	}
+	r.Commit()
+}
+
 func (r *Response) reset(w http.ResponseWriter) {
 	r.Size = noWritten
 	r.Status = http.StatusOK
 	r.ResponseWriter = w
+	r.buffered = false
+	r.bufferLimit = 0
+	r.befores = nil
+	if r.buffer != nil {
+		r.buffer.Reset()
+	}
 }
 
 func NewResponse(w http.ResponseWriter) *Response {
-	return &Response{ResponseWriter: w}
+	return &Response{ResponseWriter: w, Size: noWritten, Status: http.StatusOK}
 }
@@ -0,0 +1,69 @@
+package forest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseBuffer(t *testing.T) {
+	router := New()
+	buffer := func(c Context) error {
+		c.Response().Buffer(0)
+		return c.Next()
+	}
+	upper := func(c Context) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		resp := c.Response()
+		assert.True(t, resp.Buffered())
+		assert.Equal(t, "hello", string(resp.Body()))
+
+		resp.Status = http.StatusAccepted
+		resp.Header().Set("X-Test", "1")
+		resp.SetBody(bytes.ToUpper(resp.Body()))
+		return nil
+	}
+	router.GET("/", buffer, upper, func(c Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-Test"))
+	assert.Equal(t, "HELLO", rec.Body.String())
+}
+
+func TestResponseBufferLimit(t *testing.T) {
+	rec := httptest.NewRecorder()
+	resp := NewResponse(rec)
+	resp.reset(rec)
+	resp.Buffer(4)
+
+	resp.Write([]byte("abc"))
+	assert.True(t, resp.Buffered())
+	assert.Equal(t, 0, rec.Body.Len())
+
+	resp.Write([]byte("def"))
+	assert.False(t, resp.Buffered())
+	assert.Equal(t, "abcdef", rec.Body.String())
+	assert.Equal(t, 6, resp.Size)
+}
+
+func TestNewResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	resp := NewResponse(rec)
+	assert.False(t, resp.Written())
+	assert.Equal(t, http.StatusOK, resp.Status)
+
+	resp.WriteHeader(http.StatusCreated)
+	assert.True(t, resp.Written())
+	assert.Equal(t, 0, resp.Size)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
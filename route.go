@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 type (
@@ -22,10 +23,37 @@ type (
 		group    *Group
 		pnames   []routePname
 		handlers []HandlerFunc
+		// canonical route of alias
+		canonical *Route
+		aliases   []*Route
 	}
 	Routes []*Route
 )
 
+// wildcard reports whether the param matches multiple path segments, such as *name or {name:path}
+func (p routePname) wildcard(path string) bool {
+	segment := path[p.start:p.end]
+	return strings.HasPrefix(segment, "*") || strings.HasSuffix(segment, ":path}")
+}
+
+// samePnames reports whether a and b declare the same param names, the order is ignored
+func samePnames(a, b []routePname) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	names := make(map[string]int, len(a))
+	for _, p := range a {
+		names[p.name]++
+	}
+	for _, p := range b {
+		if names[p.name] == 0 {
+			return false
+		}
+		names[p.name]--
+	}
+	return true
+}
+
 func (rs Routes) find(method string) *Route {
 	for _, r := range rs {
 		if r.Method() == method {
@@ -44,6 +72,29 @@ func (r *Route) Named(name string, desc ...string) *Route {
 	if len(desc) > 0 {
 		r.desc = desc[0]
 	}
+	for _, alias := range r.aliases {
+		alias.Name = r.Name
+		alias.desc = r.desc
+	}
+	return r
+}
+
+// Alias makes route reachable at additional paths, but URL still returns the canonical path.
+// Alias can be called before or after Named, the name of aliases follows the canonical route.
+// The alias path must declare the same param names as the canonical route
+func (r *Route) Alias(paths ...string) *Route {
+	for _, path := range paths {
+		route := r.group.forest.addRoute(r.host, r.method, r.group.prefix+path)
+		if !samePnames(route.pnames, r.pnames) {
+			panic("forest: alias " + path + " must have the same params as " + r.path)
+		}
+		route.Name = r.Name
+		route.desc = r.desc
+		route.group = r.group
+		route.handlers = r.handlers
+		route.canonical = r
+		r.aliases = append(r.aliases, route)
+	}
 	return r
 }
 
@@ -109,6 +160,9 @@ func (r *Route) ErrorHandle(err error, c Context) {
 }
 
 func (r *Route) URL(args ...interface{}) string {
+	if r.canonical != nil {
+		return r.canonical.URL(args...)
+	}
 	if len(args) == 0 {
 		return r.path
 	}
@@ -0,0 +1,104 @@
+package session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/honmaple/forest"
+)
+
+var (
+	ErrInvalidValue = forest.ErrInvalidCookie
+	ErrExpiredValue = forest.ErrExpiredCookie
+)
+
+type (
+	// CookieStore stores session values in cookie, values are signed with
+	// HMAC-SHA256, or encrypted with AES-GCM if block key is provided
+	CookieStore struct {
+		// name is used as the MAC or AAD name, so that the value of
+		// one cookie could not be used as another one
+		name    string
+		codec   *forest.CookieCodec
+		encrypt bool
+	}
+	cookieValue struct {
+		ID     string
+		Values map[string]interface{}
+	}
+)
+
+func (s *CookieStore) Load(value string) (string, map[string]interface{}, error) {
+	var (
+		b   string
+		err error
+	)
+	if s.encrypt {
+		b, err = s.codec.Decrypt(s.name, value)
+	} else {
+		b, err = s.codec.Verify(s.name, value)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	v := cookieValue{}
+	if err := gob.NewDecoder(bytes.NewReader([]byte(b))).Decode(&v); err != nil {
+		return "", nil, err
+	}
+	if v.Values == nil {
+		v.Values = make(map[string]interface{})
+	}
+	return v.ID, v.Values, nil
+}
+
+func (s *CookieStore) Save(id string, values map[string]interface{}, maxAge int) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(cookieValue{ID: id, Values: values}); err != nil {
+		return "", err
+	}
+	var expires time.Time
+	if maxAge > 0 {
+		expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+	if s.encrypt {
+		return s.codec.Encrypt(s.name, buf.String(), expires)
+	}
+	return s.codec.Sign(s.name, buf.String(), expires)
+}
+
+func (s *CookieStore) Delete(string) error {
+	return nil
+}
+
+// WithName returns a copy of store with cookie name, the middleware
+// calls it with Config.Name automatically
+func (s *CookieStore) WithName(name string) *CookieStore {
+	store := *s
+	store.name = name
+	return &store
+}
+
+// NewCookieStore creates cookie store with hash key, the optional block key
+// must be 16, 24 or 32 bytes to select AES-128, AES-192, or AES-256
+func NewCookieStore(hashKey []byte, blockKey ...[]byte) (*CookieStore, error) {
+	if len(hashKey) == 0 {
+		return nil, errors.New("session: hash key is required")
+	}
+	codec := new(forest.CookieCodec)
+	codec.SetHashKeys(hashKey)
+	if len(blockKey) > 0 && len(blockKey[0]) > 0 {
+		if err := codec.SetBlockKeys(blockKey[0]); err != nil {
+			return nil, err
+		}
+		return &CookieStore{name: DefaultConfig.Name, codec: codec, encrypt: true}, nil
+	}
+	return &CookieStore{name: DefaultConfig.Name, codec: codec}, nil
+}
+
+// NewCookieStoreWithCodec creates cookie store with codec, which could has multiple keys for key rotation
+func NewCookieStoreWithCodec(codec *forest.CookieCodec, encrypt bool) *CookieStore {
+	return &CookieStore{name: DefaultConfig.Name, codec: codec, encrypt: encrypt}
+}
@@ -0,0 +1,243 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/honmaple/forest"
+)
+
+const (
+	contextKey = "forest.session"
+	flashKey   = "_flash"
+)
+
+type (
+	Config struct {
+		Skipper  func(forest.Context) bool
+		Store    Store
+		Name     string
+		Path     string
+		Domain   string
+		MaxAge   int
+		Secure   bool
+		HttpOnly bool
+		SameSite http.SameSite
+	}
+	Session struct {
+		ID     string
+		Values map[string]interface{}
+		IsNew  bool
+
+		oldID     string
+		modified  bool
+		destroyed bool
+	}
+	lazySession struct {
+		c       forest.Context
+		config  *Config
+		session *Session
+	}
+)
+
+var (
+	DefaultConfig = Config{
+		Name:     "session",
+		Path:     "/",
+		MaxAge:   86400 * 7,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+)
+
+func init() {
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		panic("session: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newSession() *Session {
+	return &Session{
+		ID:     newID(),
+		Values: make(map[string]interface{}),
+		IsNew:  true,
+	}
+}
+
+func (s *Session) Get(key string) interface{} {
+	return s.Values[key]
+}
+
+func (s *Session) Set(key string, value interface{}) {
+	s.Values[key] = value
+	s.modified = true
+}
+
+func (s *Session) Delete(key string) {
+	if _, ok := s.Values[key]; ok {
+		delete(s.Values, key)
+		s.modified = true
+	}
+}
+
+func (s *Session) Clear() {
+	for key := range s.Values {
+		delete(s.Values, key)
+	}
+	s.modified = true
+}
+
+func (s *Session) AddFlash(value interface{}) {
+	flashes, _ := s.Values[flashKey].([]interface{})
+	s.Values[flashKey] = append(flashes, value)
+	s.modified = true
+}
+
+// Flashes returns and removes all flash messages
+func (s *Session) Flashes() []interface{} {
+	flashes, ok := s.Values[flashKey].([]interface{})
+	if !ok {
+		return nil
+	}
+	delete(s.Values, flashKey)
+	s.modified = true
+	return flashes
+}
+
+// Regenerate changes session id and keeps values, the old session will be deleted from store
+func (s *Session) Regenerate() {
+	if s.oldID == "" && !s.IsNew {
+		s.oldID = s.ID
+	}
+	s.ID = newID()
+	s.modified = true
+}
+
+// Destroy deletes session from store and expires the cookie
+func (s *Session) Destroy() {
+	s.destroyed = true
+}
+
+func (s *Session) Modified() bool {
+	return s.modified
+}
+
+func (l *lazySession) get() *Session {
+	if l.session != nil {
+		return l.session
+	}
+	if cookie, err := l.c.Cookie(l.config.Name); err == nil && cookie.Value != "" {
+		id, values, err := l.config.Store.Load(cookie.Value)
+		if err == nil && values != nil {
+			l.session = &Session{ID: id, Values: values}
+			return l.session
+		}
+	}
+	l.session = newSession()
+	return l.session
+}
+
+func (l *lazySession) cookie(value string, maxAge int) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     l.config.Name,
+		Value:    value,
+		Path:     l.config.Path,
+		Domain:   l.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   l.config.Secure,
+		HttpOnly: l.config.HttpOnly,
+		SameSite: l.config.SameSite,
+	}
+	if maxAge > 0 {
+		cookie.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+	return cookie
+}
+
+func (l *lazySession) save() {
+	s := l.session
+	if s == nil {
+		return
+	}
+	store := l.config.Store
+	if s.destroyed {
+		if !s.IsNew {
+			l.error(store.Delete(s.ID))
+		}
+		if s.oldID != "" {
+			l.error(store.Delete(s.oldID))
+		}
+		l.c.SetCookie(l.cookie("", -1))
+		return
+	}
+	if !s.modified {
+		return
+	}
+	if s.oldID != "" {
+		l.error(store.Delete(s.oldID))
+	}
+	value, err := store.Save(s.ID, s.Values, l.config.MaxAge)
+	if err != nil {
+		l.error(err)
+		return
+	}
+	l.c.SetCookie(l.cookie(value, l.config.MaxAge))
+}
+
+func (l *lazySession) error(err error) {
+	if err == nil {
+		return
+	}
+	if logger := l.c.Logger(); logger != nil {
+		logger.Errorln("session:", err.Error())
+	}
+}
+
+// Default returns the session of current request, the session is loaded lazily
+func Default(c forest.Context) *Session {
+	if l, ok := c.Get(contextKey).(*lazySession); ok {
+		return l.get()
+	}
+	return nil
+}
+
+func Middleware(store Store) forest.HandlerFunc {
+	config := DefaultConfig
+	config.Store = store
+	return MiddlewareWithConfig(config)
+}
+
+func MiddlewareWithConfig(config Config) forest.HandlerFunc {
+	if config.Store == nil {
+		panic("session: store is required")
+	}
+	if config.Name == "" {
+		config.Name = DefaultConfig.Name
+	}
+	if config.Path == "" {
+		config.Path = DefaultConfig.Path
+	}
+	if store, ok := config.Store.(*CookieStore); ok && store.name != config.Name {
+		config.Store = store.WithName(config.Name)
+	}
+	return func(c forest.Context) error {
+		if config.Skipper != nil && config.Skipper(c) {
+			return c.Next()
+		}
+		l := &lazySession{c: c, config: &config}
+		c.Set(contextKey, l)
+		c.Response().Before(l.save)
+		return c.Next()
+	}
+}
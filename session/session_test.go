@@ -0,0 +1,158 @@
+package session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/honmaple/forest"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRequest(router http.Handler, path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func testStore(t *testing.T, store Store) {
+	router := forest.New()
+	router.Use(Middleware(store))
+	router.GET("/set", func(c forest.Context) error {
+		s := Default(c)
+		s.Set("user", "forest")
+		s.AddFlash("hello")
+		return c.String(http.StatusOK, "")
+	})
+	router.GET("/get", func(c forest.Context) error {
+		s := Default(c)
+		user, _ := s.Get("user").(string)
+		return c.String(http.StatusOK, "%s %v", user, s.Flashes())
+	})
+	router.GET("/none", func(c forest.Context) error {
+		return nil
+	})
+	router.GET("/destroy", func(c forest.Context) error {
+		Default(c).Destroy()
+		return nil
+	})
+
+	rec := testRequest(router, "/none")
+	assert.Empty(t, rec.Result().Cookies())
+
+	rec = testRequest(router, "/set")
+	cookies := rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+
+	rec = testRequest(router, "/get", cookies...)
+	assert.Equal(t, "forest [hello]", rec.Body.String())
+	cookies = rec.Result().Cookies()
+	assert.Len(t, cookies, 1)
+
+	rec = testRequest(router, "/get", cookies...)
+	assert.Equal(t, "forest []", rec.Body.String())
+
+	rec = testRequest(router, "/destroy", cookies...)
+	assert.Equal(t, -1, rec.Result().Cookies()[0].MaxAge)
+
+	rec = testRequest(router, "/get", &http.Cookie{Name: "session", Value: "invalid"})
+	assert.Equal(t, " []", rec.Body.String())
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestCookieStore(t *testing.T) {
+	store, err := NewCookieStore([]byte("hash-key"))
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	store, err = NewCookieStore([]byte("hash-key"), []byte("1234567890123456"))
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	value, err := store.Save("id", map[string]interface{}{"a": 1}, 0)
+	assert.NoError(t, err)
+	id, values, err := store.Load(value)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", id)
+	assert.Equal(t, 1, values["a"])
+
+	tampered := []byte(value)
+	tampered[len(tampered)/2] ^= 1
+	_, _, err = store.Load(string(tampered))
+	assert.Equal(t, ErrInvalidValue, err)
+}
+
+func TestCookieStoreName(t *testing.T) {
+	store, err := NewCookieStore([]byte("hash-key"))
+	assert.NoError(t, err)
+
+	value, err := store.Save("id", map[string]interface{}{"a": 1}, 0)
+	assert.NoError(t, err)
+
+	_, _, err = store.WithName("other").Load(value)
+	assert.Equal(t, ErrInvalidValue, err)
+
+	newRouter := func(name string) *forest.Forest {
+		router := forest.New()
+		router.Use(MiddlewareWithConfig(Config{Name: name, Store: store}))
+		router.GET("/set", func(c forest.Context) error {
+			Default(c).Set("user", "forest")
+			return nil
+		})
+		router.GET("/get", func(c forest.Context) error {
+			user, _ := Default(c).Get("user").(string)
+			return c.String(http.StatusOK, user)
+		})
+		return router
+	}
+	router1, router2 := newRouter("sid1"), newRouter("sid2")
+
+	cookies := testRequest(router1, "/set").Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "forest", testRequest(router1, "/get", cookies...).Body.String())
+
+	// the value of sid1 can't be used as sid2
+	cookie := *cookies[0]
+	cookie.Name = "sid2"
+	assert.Equal(t, "", testRequest(router2, "/get", &cookie).Body.String())
+}
+
+func TestExpiredSession(t *testing.T) {
+	store, err := NewCookieStore([]byte("hash-key"))
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, gob.NewEncoder(buf).Encode(cookieValue{ID: "id", Values: map[string]interface{}{"a": 1}}))
+	value, err := store.codec.Sign(store.name, buf.String(), time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+
+	_, _, err = store.Load(value)
+	assert.Equal(t, ErrExpiredValue, err)
+
+	router := forest.New()
+	router.Use(Middleware(store))
+	router.GET("/get", func(c forest.Context) error {
+		a, _ := Default(c).Get("a").(int)
+		return c.String(http.StatusOK, "%d", a)
+	})
+	rec := testRequest(router, "/get", &http.Cookie{Name: DefaultConfig.Name, Value: value})
+	assert.Equal(t, "0", rec.Body.String())
+
+	memory := NewMemoryStore()
+	_, err = memory.Save("id", map[string]interface{}{"a": 1}, 60)
+	assert.NoError(t, err)
+	memory.items["id"] = memoryItem{values: map[string]interface{}{"a": 1}, expires: time.Now().Add(-time.Second)}
+	_, values, err := memory.Load("id")
+	assert.NoError(t, err)
+	assert.Nil(t, values)
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Store loads and saves session values, the value is what stored in cookie,
+	// which could be session id or encoded session values
+	Store interface {
+		Load(value string) (string, map[string]interface{}, error)
+		Save(id string, values map[string]interface{}, maxAge int) (string, error)
+		Delete(id string) error
+	}
+	MemoryStore struct {
+		mu         sync.Mutex
+		items      map[string]memoryItem
+		gcInterval time.Duration
+		gcTime     time.Time
+	}
+	memoryItem struct {
+		values  map[string]interface{}
+		expires time.Time
+	}
+)
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+	return m
+}
+
+func (s *MemoryStore) Load(id string) (string, map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return id, nil, nil
+	}
+	if !item.expires.IsZero() && item.expires.Before(time.Now()) {
+		delete(s.items, id)
+		return id, nil, nil
+	}
+	return id, copyValues(item.values), nil
+}
+
+func (s *MemoryStore) Save(id string, values map[string]interface{}, maxAge int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	item := memoryItem{values: copyValues(values)}
+	if maxAge > 0 {
+		item.expires = now.Add(time.Duration(maxAge) * time.Second)
+	}
+	s.items[id] = item
+
+	if now.Sub(s.gcTime) > s.gcInterval {
+		for k, v := range s.items {
+			if !v.expires.IsZero() && v.expires.Before(now) {
+				delete(s.items, k)
+			}
+		}
+		s.gcTime = now
+	}
+	return id, nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, id)
+	return nil
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items:      make(map[string]memoryItem),
+		gcInterval: time.Minute,
+		gcTime:     time.Now(),
+	}
+}